@@ -0,0 +1,46 @@
+package datauri
+
+// Report tells how a Data URI deviates from RFC 2397, see Analyze.
+type Report struct {
+	// StrictErr is the error decoding the input with WithStrictRFC2397,
+	// nil if it conforms to RFC 2397.
+	StrictErr error
+	// LenientErr is the error decoding the input with the default,
+	// lenient, decoder. nil if it decodes despite its deviations.
+	LenientErr error
+	// Corrections lists the deviations of the input which
+	// the lenient decoder had to correct.
+	Corrections []Correction
+}
+
+// Conformant reports whether the input conforms to RFC 2397.
+func (r *Report) Conformant() bool {
+	return r.StrictErr == nil
+}
+
+// Required returns the distinct kinds of corrections the input needs
+// to be decoded, in order of first occurrence.
+func (r *Report) Required() []CorrectionKind {
+	var kinds []CorrectionKind
+	seen := make(map[CorrectionKind]bool)
+	for _, c := range r.Corrections {
+		if !seen[c.Kind] {
+			seen[c.Kind] = true
+			kinds = append(kinds, c.Kind)
+		}
+	}
+	return kinds
+}
+
+// Analyze decodes s both in strict and lenient modes and reports
+// which leniencies were required for it to decode, so that producers
+// of non-conformant Data URIs can be given precise feedback.
+func Analyze(s string) Report {
+	var r Report
+	_, r.StrictErr = DecodeString(s, WithStrictRFC2397())
+	_, r.LenientErr = DecodeString(s, WithCorrections(&r.Corrections))
+	if r.LenientErr != nil {
+		r.Corrections = nil
+	}
+	return r
+}
@@ -0,0 +1,44 @@
+package datauri
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		Input              string
+		ExpectedConformant bool
+		ExpectedLenientErr bool
+		ExpectedRequired   []CorrectionKind
+	}{
+		{`data:text/plain;charset=utf-8;base64,aGV5YQ==`, true, false, nil},
+		{`data:text/plain;utf8;base64,aGV5` + "\n" + `YQ==`, false, false, []CorrectionKind{CorrectionBareFlag, CorrectionLineBreak}},
+		{`data:;base64,aG` + "\n" + `V5` + "\n" + `YQ==`, false, false, []CorrectionKind{CorrectionLineBreak}},
+		{`data:text/plain;foo;base64,aGV5YQ==`, false, true, nil},
+	}
+	for _, test := range tests {
+		r := Analyze(test.Input)
+		if r.Conformant() != test.ExpectedConformant {
+			t.Errorf("%q: expected Conformant() to be %v, got %v", test.Input, test.ExpectedConformant, r.StrictErr)
+		}
+		if (r.LenientErr != nil) != test.ExpectedLenientErr {
+			t.Errorf("%q: unexpected lenient error %v", test.Input, r.LenientErr)
+		}
+		if got := r.Required(); !reflect.DeepEqual(got, test.ExpectedRequired) {
+			t.Errorf("%q: expected %v, got %v", test.Input, test.ExpectedRequired, got)
+		}
+	}
+}
+
+func TestWithStrictRFC2397(t *testing.T) {
+	_, err := DecodeString(`data:text/plain;utf8,heya`, WithStrictRFC2397())
+	if !errors.Is(err, ErrNotConformant) {
+		t.Errorf("Expected %v, got %v", ErrNotConformant, err)
+	}
+	expected := `datauri: not conformant to RFC 2397: bare_flag "utf8" at offset 16`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,196 @@
+package datauri
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Formats of the archives written by Archive and read by Unarchive.
+const (
+	ArchiveTar = "tar"
+	ArchiveZip = "zip"
+)
+
+// preferredExtensions lists the extensions used for common media types,
+// where mime.ExtensionsByType yields several in alphabetical order,
+// or none depending on the system.
+var preferredExtensions = map[string]string{
+	"application/gzip":         ".gz",
+	"application/json":         ".json",
+	"application/octet-stream": ".bin",
+	"application/pdf":          ".pdf",
+	"application/xml":          ".xml",
+	"application/zip":          ".zip",
+	"audio/mpeg":               ".mp3",
+	"font/woff2":               ".woff2",
+	"image/avif":               ".avif",
+	"image/gif":                ".gif",
+	"image/jpeg":               ".jpg",
+	"image/png":                ".png",
+	"image/svg+xml":            ".svg",
+	"image/webp":               ".webp",
+	"image/x-icon":             ".ico",
+	"text/css":                 ".css",
+	"text/csv":                 ".csv",
+	"text/html":                ".html",
+	"text/javascript":          ".js",
+	"text/markdown":            ".md",
+	"text/plain":               ".txt",
+	"video/mp4":                ".mp4",
+}
+
+// extensionFor returns the usual file extension of mt, with its leading
+// dot, or an empty string if there is none.
+func extensionFor(mt *MediaType) string {
+	ct := strings.ToLower(mt.ContentType())
+	if ext, ok := preferredExtensions[ct]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(ct); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// SuggestedExtension returns the usual file extension of the media type
+// of du, with its leading dot, e.g. ".png" for image/png, to write its
+// payload to a file. Common media types have a fixed extension, the
+// others are looked up with mime.ExtensionsByType. It fails if the
+// media type has no known extension.
+func (du *DataURI) SuggestedExtension() (string, error) {
+	if ext := extensionFor(&du.MediaType); ext != "" {
+		return ext, nil
+	}
+	return "", fmt.Errorf("datauri: no known extension for %s", du.ContentType())
+}
+
+// Archive writes the payloads of dus as the files of an archive
+// in format, ArchiveTar or ArchiveZip. The files are named after the keys
+// of dus, with the extension of their media type added when they have none,
+// and written in the order of their names.
+func Archive(w io.Writer, format string, dus map[string]*DataURI) error {
+	names := make([]string, 0, len(dus))
+	for name := range dus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch format {
+	case ArchiveTar:
+		tw := tar.NewWriter(w)
+		for _, name := range names {
+			du := dus[name]
+			hdr := &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     archiveName(name, du),
+				Mode:     0o644,
+				Size:     int64(len(du.Data)),
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(du.Data); err != nil {
+				return err
+			}
+		}
+		return tw.Close()
+	case ArchiveZip:
+		zw := zip.NewWriter(w)
+		for _, name := range names {
+			du := dus[name]
+			fw, err := zw.Create(archiveName(name, du))
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(du.Data); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	}
+	return fmt.Errorf("datauri: unknown archive format %q", format)
+}
+
+func archiveName(name string, du *DataURI) string {
+	if path.Ext(name) != "" {
+		return name
+	}
+	return name + extensionFor(&du.MediaType)
+}
+
+// Unarchive reads the regular files of an archive in format, ArchiveTar
+// or ArchiveZip, as DataURIs keyed by file name. Their media type is
+// detected with DetectWithHints, using the file name.
+func Unarchive(r io.Reader, format string) (map[string]*DataURI, error) {
+	dus := make(map[string]*DataURI)
+	switch format {
+	case ArchiveTar:
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return dus, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			dus[hdr.Name] = newDetected(data, hdr.Name)
+		}
+	case ArchiveZip:
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if !f.Mode().IsRegular() {
+				continue
+			}
+			data, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			dus[f.Name] = newDetected(data, f.Name)
+		}
+		return dus, nil
+	}
+	return nil, fmt.Errorf("datauri: unknown archive format %q", format)
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close() //nolint:errcheck
+	return io.ReadAll(rc)
+}
+
+// newDetected returns a DataURI of data, whose media type is detected
+// with DetectWithHints, or is application/octet-stream if the detected
+// one is invalid, as may be those of the registered Detectors or of the
+// system mime.types files.
+func newDetected(data []byte, filename string) *DataURI {
+	du, err := NewChecked(data, DetectWithHints(data, filename))
+	if err != nil {
+		return New(data, "application/octet-stream")
+	}
+	return du
+}
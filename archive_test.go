@@ -0,0 +1,111 @@
+package datauri
+
+import (
+	"archive/tar"
+	"bytes"
+	"mime"
+	"testing"
+)
+
+func TestArchive(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	dus := map[string]*DataURI{
+		"logo":      New(png, "image/png"),
+		"notes.txt": New([]byte("heya"), "text/plain", "charset", "utf-8"),
+		"data":      New([]byte(`{"a":1}`), "application/json"),
+	}
+	for _, format := range []string{ArchiveTar, ArchiveZip} {
+		var buf bytes.Buffer
+		if err := Archive(&buf, format, dus); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Unarchive(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]string{
+			"logo.png":  "image/png",
+			"notes.txt": "text/plain",
+			"data.json": "application/json",
+		}
+		if len(got) != len(expected) {
+			t.Errorf("%s: expected %d files, got %d", format, len(expected), len(got))
+		}
+		for name, ct := range expected {
+			du, ok := got[name]
+			if !ok {
+				t.Errorf("%s: expected %s in archive", format, name)
+				continue
+			}
+			if du.ContentType() != ct {
+				t.Errorf("%s: expected %s, got %s", name, ct, du.ContentType())
+			}
+		}
+		if du := got["logo.png"]; du != nil && !bytes.Equal(du.Data, png) {
+			t.Errorf("Expected %v, got %v", png, du.Data)
+		}
+	}
+}
+
+func TestUnarchiveInvalidMediaType(t *testing.T) {
+	// mime accepts media types without subtype, unlike NewChecked
+	if err := mime.AddExtensionType(".x-datauri-test", "nosubtype"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "notes.x-datauri-test", Mode: 0o644, Size: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("heya")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dus, err := Unarchive(&buf, ArchiveTar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	du := dus["notes.x-datauri-test"]
+	if du == nil || du.ContentType() != "application/octet-stream" || string(du.Data) != "heya" {
+		t.Errorf("Expected heya as application/octet-stream, got %v", du)
+	}
+}
+
+func TestArchiveUnknownFormat(t *testing.T) {
+	if err := Archive(&bytes.Buffer{}, "rar", nil); err == nil {
+		t.Error("Expected error")
+	}
+	if _, err := Unarchive(&bytes.Buffer{}, "rar"); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestSuggestedExtension(t *testing.T) {
+	tests := []struct {
+		MediaType string
+		Expected  string
+	}{
+		{"image/png", ".png"},
+		{"IMAGE/JPEG", ".jpg"},
+		{"application/pdf", ".pdf"},
+		{"text/plain", ".txt"},
+		{"image/x-icon", ".ico"},
+		{"application/x-unknown-thing", ""},
+	}
+	for _, test := range tests {
+		ext, err := New(nil, test.MediaType).SuggestedExtension()
+		if test.Expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", test.MediaType, ext)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.MediaType, err)
+		} else if ext != test.Expected {
+			t.Errorf("%s: expected %s, got %s", test.MediaType, test.Expected, ext)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//go:build datauri_unsafe
+
+package datauri
+
+// DefaultSlabSize is the size of the slabs of an Arena created
+// with a slab size of 0.
+const DefaultSlabSize = 1 << 20
+
+// Arena decodes Data URIs with their payloads carved out of large slabs
+// of memory, which are all freed at once by Free, so that decoding
+// many Data URIs puts next to no pressure on the garbage collector.
+//
+// Arena is experimental, and only available when built with the
+// datauri_unsafe tag. It is not safe for concurrent use.
+type Arena struct {
+	slabSize int
+	slab     []byte // current slab
+	used     int    // in the current slab
+	spare    [][]byte
+	full     [][]byte
+}
+
+// NewArena returns an Arena allocating slabs of slabSize bytes,
+// or DefaultSlabSize if slabSize is 0.
+func NewArena(slabSize int) *Arena {
+	if slabSize <= 0 {
+		slabSize = DefaultSlabSize
+	}
+	return &Arena{slabSize: slabSize}
+}
+
+// DecodeString is like the package's DecodeString, but the payload
+// of the returned DataURI is allocated in a.
+// It must not be used after a call to Free.
+func (a *Arena) DecodeString(s string, opts ...Option) (*DataURI, error) {
+	return DecodeString(s, append(opts, func(o *options) {
+		o.alloc = a.alloc
+	})...)
+}
+
+// Free frees all the payloads decoded by a at once, keeping
+// the slabs for the next decodings.
+func (a *Arena) Free() {
+	if a.slab != nil {
+		a.spare = append(a.spare, a.slab)
+	}
+	a.spare = append(a.spare, a.full...)
+	a.full = a.full[:0]
+	a.slab, a.used = nil, 0
+}
+
+func (a *Arena) alloc(n int) []byte {
+	if n > a.slabSize {
+		// too large to share a slab, left to the garbage collector
+		return make([]byte, n)
+	}
+	if a.slab == nil || a.used+n > len(a.slab) {
+		if a.slab != nil {
+			a.full = append(a.full, a.slab)
+		}
+		a.slab, a.used = a.nextSlab(), 0
+	}
+	b := a.slab[a.used : a.used+n : a.used+n]
+	a.used += n
+	return b
+}
+
+func (a *Arena) nextSlab() []byte {
+	if n := len(a.spare); n > 0 {
+		slab := a.spare[n-1]
+		a.spare = a.spare[:n-1]
+		return slab
+	}
+	return make([]byte, a.slabSize)
+}
@@ -0,0 +1,56 @@
+//go:build datauri_unsafe
+
+package datauri
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	a := NewArena(64)
+	for round := 0; round < 3; round++ {
+		inputs := []string{
+			`data:text/plain;charset=utf-8;base64,aGV5YQ==`,
+			`data:,A%20brief%20note`,
+			New(bytes.Repeat([]byte("heya"), 40), "text/plain").String(),
+			`data:,` + strings.Repeat("a", 100),
+		}
+		var dus []*DataURI
+		for _, s := range inputs {
+			du, err := a.DecodeString(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dus = append(dus, du)
+		}
+		for i, s := range inputs {
+			expected, err := DecodeString(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dus[i].Data, expected.Data) {
+				t.Errorf("Expected %q, got %q", expected.Data, dus[i].Data)
+			}
+		}
+		a.Free()
+	}
+	if len(a.spare) == 0 {
+		t.Error("Expected slabs to be kept for reuse")
+	}
+}
+
+func BenchmarkArenaDecodeString(b *testing.B) {
+	s := New(largePayload()[:4096], "application/octet-stream").String()
+	a := NewArena(0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := a.DecodeString(s); err != nil {
+			b.Fatal(err)
+		}
+		if i%100 == 99 {
+			a.Free()
+		}
+	}
+}
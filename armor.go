@@ -0,0 +1,76 @@
+package datauri
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+const (
+	armorHeader = "-----BEGIN DATA URI-----"
+	armorFooter = "-----END DATA URI-----"
+
+	// DefaultArmorWidth is the line width used by Armor
+	// when a non-positive width is given.
+	DefaultArmorWidth = 76
+)
+
+// Armor returns the Data URI string of du wrapped in lines of at most
+// width characters, enclosed between a header and a footer line:
+//
+//	-----BEGIN DATA URI-----
+//	data:text/plain;charset=utf-8;base64,VGhlIHF1aWNrIGJyb3duIGZveCBqdW1w
+//	cyBvdmVyIHRoZSBsYXp5IGRvZw==
+//	-----END DATA URI-----
+//
+// This is useful to embed data URIs in contexts that reflow text,
+// such as YAML block scalars or plain text emails. Use Dearmor to
+// get the DataURI back.
+func Armor(du *DataURI, width int) string {
+	if width <= 0 {
+		width = DefaultArmorWidth
+	}
+	s := du.String()
+
+	var b strings.Builder
+	b.Grow(len(s) + len(s)/width + len(armorHeader) + len(armorFooter) + 3)
+	b.WriteString(armorHeader)
+	b.WriteByte('\n')
+	for len(s) > width {
+		b.WriteString(s[:width])
+		b.WriteByte('\n')
+		s = s[width:]
+	}
+	if s != "" {
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+	b.WriteString(armorFooter)
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// Dearmor decodes a Data URI wrapped by Armor.
+//
+// It is tolerant to the modifications usually done by text reflowing:
+// text before the header or after the footer is ignored,
+// and all the whitespace between them (indentation, line breaks
+// at any position, CRLF line endings) is removed before decoding.
+func Dearmor(s string) (*DataURI, error) {
+	start := strings.Index(s, armorHeader)
+	if start < 0 {
+		return nil, errors.New("datauri: armor header not found")
+	}
+	s = s[start+len(armorHeader):]
+	end := strings.Index(s, armorFooter)
+	if end < 0 {
+		return nil, errors.New("datauri: armor footer not found")
+	}
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s[:end])
+	return DecodeString(s)
+}
@@ -0,0 +1,61 @@
+package datauri
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArmor(t *testing.T) {
+	du := New([]byte("The quick brown fox jumps over the lazy dog"), "text/plain", "charset", "utf-8")
+	armored := Armor(du, 20)
+	expected := `-----BEGIN DATA URI-----
+data:text/plain;char
+set=utf-8;base64,VGh
+lIHF1aWNrIGJyb3duIGZ
+veCBqdW1wcyBvdmVyIHR
+oZSBsYXp5IGRvZw==
+-----END DATA URI-----
+`
+	if armored != expected {
+		t.Errorf("Expected %s, got %s", expected, armored)
+	}
+	for _, line := range strings.Split(Armor(du, 0), "\n") {
+		if len(line) > DefaultArmorWidth {
+			t.Errorf("Line too long: %s", line)
+		}
+	}
+}
+
+func TestDearmor(t *testing.T) {
+	du := New([]byte("The quick brown fox jumps over the lazy dog"), "text/plain", "charset", "utf-8")
+	tests := []struct {
+		Input       string
+		ExpectedErr bool
+	}{
+		{Armor(du, 20), false},
+		{Armor(du, 0), false},
+		{"key: |\n  " + strings.ReplaceAll(Armor(du, 30), "\n", "\r\n  "), false},
+		{"Hello,\n\n" + strings.ReplaceAll(Armor(du, 10), "\n", " \n") + "\nRegards", false},
+		{strings.TrimPrefix(Armor(du, 20), armorHeader), true},
+		{strings.TrimSuffix(Armor(du, 20), armorFooter+"\n"), true},
+		{armorHeader + "\ndata:text/plain\n" + armorFooter, true},
+	}
+	for _, test := range tests {
+		got, err := Dearmor(test.Input)
+		if test.ExpectedErr {
+			if err == nil {
+				t.Errorf("Expected error for %q", test.Input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if ok, err := equal(got, du); err != nil {
+			t.Error(err)
+		} else if !ok {
+			t.Errorf("Expected %v, got %v", du, got)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package datauri
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// The decoders of base64 payloads, whole or streamed, share the helpers
+// below, so that they accept the same payloads: either alphabet, the
+// standard one or the URL-safe one, is detected, but not both, the
+// padding may be missing and line breaks are ignored.
+
+// base64Encoding returns the encoding of a base64 payload
+// of the URL-safe alphabet or not, padded or not.
+func base64Encoding(urlSafe, padded bool) *base64.Encoding {
+	switch {
+	case urlSafe && padded:
+		return base64.URLEncoding
+	case urlSafe:
+		return base64.RawURLEncoding
+	case padded:
+		return base64.StdEncoding
+	}
+	return base64.RawStdEncoding
+}
+
+// isPaddedBase64 reports whether the base64 payload s is padded, or
+// should be: when it ends with a '=' or is made of whole quanta,
+// line breaks ignored.
+func isPaddedBase64[T input](s T) bool {
+	s = trimLineBreaks(s)
+	n := len(s) - countByte(s, '\n') - countByte(s, '\r')
+	return n%4 == 0 || hasSuffix(s, "=")
+}
+
+// indexURLSafeBase64 returns the index in s of the first character
+// of the URL-safe base64 alphabet, '-' or '_', or -1.
+func indexURLSafeBase64[T input](s T) int {
+	i := indexByte(s, '-')
+	if j := indexByte(s, '_'); j >= 0 && (i < 0 || j < i) {
+		i = j
+	}
+	return i
+}
+
+// base64Reader normalizes the base64 payload read from r, as it is read:
+// it detects its alphabet and translates it to the standard one, and pads
+// it when it ends unpadded. A payload mixing both alphabets fails with
+// a base64.CorruptInputError.
+type base64Reader struct {
+	r       io.Reader
+	off     int64 // of the next byte, for errors
+	urlSafe bool
+	std     bool
+	chars   int64 // read, line breaks excluded
+	padded  bool
+	pad     int // padding left to return, at the end of r
+	eof     bool
+}
+
+func (b *base64Reader) Read(p []byte) (int, error) {
+	if b.eof {
+		n := copy(p, "=="[:b.pad])
+		if b.pad -= n; b.pad == 0 {
+			return n, io.EOF
+		}
+		return n, nil
+	}
+	n, err := b.r.Read(p)
+	for i, c := range p[:n] {
+		switch c {
+		case '\r', '\n':
+			continue
+		case '-', '_':
+			if b.std {
+				return i, base64.CorruptInputError(b.off + int64(i))
+			}
+			b.urlSafe = true
+			p[i] = '+'
+			if c == '_' {
+				p[i] = '/'
+			}
+		case '+', '/':
+			if b.urlSafe {
+				return i, base64.CorruptInputError(b.off + int64(i))
+			}
+			b.std = true
+		case '=':
+			b.padded = true
+		}
+		b.chars++
+	}
+	b.off += int64(n)
+	if err == io.EOF {
+		b.eof = true
+		if !b.padded && b.chars%4 >= 2 {
+			b.pad = int(4 - b.chars%4)
+			return n, nil
+		}
+	}
+	return n, err
+}
+
+// newBase64Decoder returns a reader decoding the base64 payload read
+// from r, of either alphabet, padded or not, off being the offset of
+// the payload in the Data URI, for errors.
+func newBase64Decoder(r io.Reader, off int) io.Reader {
+	return base64.NewDecoder(base64.StdEncoding, &base64Reader{r: r, off: int64(off)})
+}
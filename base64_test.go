@@ -0,0 +1,103 @@
+package datauri
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// base64Fixtures are decoded the same by all the decoders
+// of base64 payloads, whole or streamed: of either alphabet,
+// padded or not, and with line breaks or not.
+var base64Fixtures = []struct {
+	InputString  string
+	Expected     string // empty for an invalid payload
+	RandomAccess bool   // whether OpenReaderAt can read it
+}{
+	{"data:;base64,aGV5YQ==", "heya", true},
+	{"data:;base64,-_-_", "\xfb\xff\xbf", true},
+	{"data:;base64,PDw_Pz4-", "<<??>>", true},
+	{"data:;base64,PDw/Pz4+", "<<??>>", true},
+	{"data:;base64,PDw_Pz4-YQ==", "<<??>>a", true},
+	{"data:;base64,PDw_Pz4+", "", true},
+	{"data:;base64,PDw/Pz4-", "", true},
+	{"data:;base64,aGV5YQ", "heya", true},
+	{"data:;base64,aGV5", "hey", true},
+	{"data:;base64,-_8", "\xfb\xff", true},
+	{"data:;base64,PDw_Pz4-YQ", "<<??>>a", true},
+	{"data:;base64,aGV5Y", "", true},
+	{"data:;base64,aGV5YQ=", "", true},
+	{"data:;base64,aGV5\r\nYQ==\r\n", "heya", false},
+	{"data:;base64,aGV5\r\nYQ\r\n", "heya", false},
+	{"data:;base64,PDw_\nPz4-\n", "<<??>>", false},
+}
+
+func TestBase64Decoders(t *testing.T) {
+	decoders := []struct {
+		Name   string
+		Decode func(s string) ([]byte, error)
+	}{
+		{"DecodeString", func(s string) ([]byte, error) {
+			du, err := DecodeString(s)
+			if err != nil {
+				return nil, err
+			}
+			return du.Data, nil
+		}},
+		{"NewReader", func(s string) ([]byte, error) {
+			r, err := NewReader(strings.NewReader(s))
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(r)
+		}},
+		{"Transcode", func(s string) ([]byte, error) {
+			var b bytes.Buffer
+			if err := Transcode(&b, strings.NewReader(s), EncodingASCII); err != nil {
+				return nil, err
+			}
+			du, err := DecodeString(b.String())
+			if err != nil {
+				return nil, err
+			}
+			return du.Data, nil
+		}},
+		{"DataRange", func(s string) ([]byte, error) {
+			return DataRange(s, 0, math.MaxInt)
+		}},
+	}
+	for _, test := range base64Fixtures {
+		for _, d := range decoders {
+			got, err := d.Decode(test.InputString)
+			if test.Expected == "" {
+				if err == nil {
+					t.Errorf("%s %s: expected an error, got %q", d.Name, test.InputString, got)
+				}
+				continue
+			}
+			if err != nil || string(got) != test.Expected {
+				t.Errorf("%s %s: expected %q, got %q, %v", d.Name, test.InputString, test.Expected, got, err)
+			}
+		}
+
+		if !test.RandomAccess {
+			continue
+		}
+		h, err := OpenReaderAt(strings.NewReader(test.InputString), int64(len(test.InputString)))
+		var got []byte
+		if err == nil {
+			got, err = io.ReadAll(io.NewSectionReader(h, 0, h.Size()))
+		}
+		if test.Expected == "" {
+			if err == nil {
+				t.Errorf("OpenReaderAt %s: expected an error, got %q", test.InputString, got)
+			}
+			continue
+		}
+		if err != nil || string(got) != test.Expected {
+			t.Errorf("OpenReaderAt %s: expected %q, got %q, %v", test.InputString, test.Expected, got, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package datauri
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BatchError is the failure to decode one of the Data URIs of a batch.
+type BatchError struct {
+	// Index is the position of the Data URI in the batch, or its
+	// offset in the text for FindAllChecked and ReplaceAllFuncChecked.
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("datauri: item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// BatchErrors is the list of BatchError collected by batch operations
+// within their error budget, see WithErrorBudget.
+type BatchErrors []*BatchError
+
+func (es BatchErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of es, to be used by errors.Is and errors.As.
+func (es BatchErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// exceeded returns the error aborting a batch operation, once es holds
+// more errors than budget, or nil.
+func (es BatchErrors) exceeded(budget int) error {
+	if len(es) <= budget {
+		return nil
+	}
+	if budget == 0 {
+		return es[0]
+	}
+	return es
+}
+
+// WithErrorBudget makes batch operations, such as DecodeAll, go on
+// past the failures of up to n items, instead of aborting at the first
+// one. The failures are collected in a BatchErrors.
+// Once more than n items failed, the operation aborts.
+func WithErrorBudget(n int) Option {
+	return func(o *options) {
+		o.errorBudget = n
+	}
+}
+
+// DecodeAll decodes each Data URI string of ss.
+//
+// By default, it aborts at the first failure and returns it as a *BatchError.
+// With WithErrorBudget, failed items are left nil in the returned slice,
+// and their errors are returned along with it as BatchErrors.
+func DecodeAll(ss []string, opts ...Option) ([]*DataURI, error) {
+	o := newOptions(opts)
+	dus := make([]*DataURI, len(ss))
+	var errs BatchErrors
+	for i, s := range ss {
+		du, err := DecodeString(s, opts...)
+		if err != nil {
+			errs = append(errs, &BatchError{Index: i, Err: err})
+			if err := errs.exceeded(o.errorBudget); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		dus[i] = du
+	}
+	if len(errs) > 0 {
+		return dus, errs
+	}
+	return dus, nil
+}
@@ -0,0 +1,57 @@
+package datauri
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeAll(t *testing.T) {
+	ss := []string{
+		`data:,a`,
+		`data:text/plain`,
+		`data:,b`,
+		`data:;base64,****`,
+		`data:,c`,
+	}
+
+	_, err := DecodeAll(ss)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 {
+		t.Errorf("Expected failure of item 1, got %v", err)
+	}
+	if !errors.Is(err, ErrMissingComma) {
+		t.Errorf("Expected the error to wrap ErrMissingComma, got %v", err)
+	}
+
+	dus, err := DecodeAll(ss, WithErrorBudget(2))
+	var errs BatchErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected BatchErrors, got %v", err)
+	}
+	if len(errs) != 2 || errs[0].Index != 1 || errs[1].Index != 3 {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+	if len(dus) != len(ss) {
+		t.Fatalf("Expected %d results, got %d", len(ss), len(dus))
+	}
+	for i, expected := range []string{"a", "", "b", "", "c"} {
+		if expected == "" {
+			if dus[i] != nil {
+				t.Errorf("Expected item %d to be nil", i)
+			}
+			continue
+		}
+		if dus[i] == nil || string(dus[i].Data) != expected {
+			t.Errorf("Expected item %d to be %q, got %v", i, expected, dus[i])
+		}
+	}
+
+	if _, err := DecodeAll(ss, WithErrorBudget(1)); !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("Expected to abort after 2 errors, got %v", err)
+	}
+
+	dus, err = DecodeAll(ss[:1], WithErrorBudget(1))
+	if err != nil || len(dus) != 1 {
+		t.Errorf("Unexpected result %v, %v", dus, err)
+	}
+}
@@ -0,0 +1,80 @@
+package datauri
+
+import "fmt"
+
+// Builder builds a DataURI step by step, as a more readable alternative
+// to New once there are several parameters:
+//
+//	du, err := datauri.NewBuilder().
+//		MediaType("image", "png").
+//		Param("name", "logo").
+//		Encoding(datauri.EncodingBase64).
+//		Data(b).
+//		Build()
+//
+// Its methods return the Builder, to chain the calls.
+type Builder struct {
+	du DataURI
+}
+
+// NewBuilder returns a Builder of a base64 encoded DataURI,
+// of the text/plain media type unless MediaType is called.
+func NewBuilder() *Builder {
+	return &Builder{
+		du: DataURI{
+			MediaType: MediaType{
+				Type:    "text",
+				Subtype: "plain",
+				Params:  map[string]string{},
+			},
+			Encoding: EncodingBase64,
+		},
+	}
+}
+
+// MediaType sets the media type and subtype, keeping the parameters.
+func (b *Builder) MediaType(t, subtype string) *Builder {
+	b.du.Type, b.du.Subtype = t, subtype
+	return b
+}
+
+// Param sets the parameter attribute to value.
+func (b *Builder) Param(attribute, value string) *Builder {
+	b.du.Params[attribute] = value
+	return b
+}
+
+// Encoding sets the encoding of the payload,
+// EncodingBase64 or EncodingASCII.
+func (b *Builder) Encoding(encoding string) *Builder {
+	b.du.Encoding = encoding
+	return b
+}
+
+// Data sets the payload. data is not copied.
+func (b *Builder) Data(data []byte) *Builder {
+	b.du.Data = data
+	return b
+}
+
+// Build returns the DataURI built by b, or an error if its media type,
+// a parameter attribute or its encoding is invalid.
+// b can be used again, the DataURI does not share its parameters.
+func (b *Builder) Build() (*DataURI, error) {
+	if !isToken(b.du.Type) || !isToken(b.du.Subtype) {
+		return nil, fmt.Errorf("datauri: invalid mediatype %q", b.du.Type+"/"+b.du.Subtype)
+	}
+	params := make(map[string]string, len(b.du.Params))
+	for k, v := range b.du.Params {
+		if !isToken(k) {
+			return nil, fmt.Errorf("datauri: invalid param attribute %q", k)
+		}
+		params[k] = v
+	}
+	if b.du.Encoding != EncodingBase64 && b.du.Encoding != EncodingASCII {
+		return nil, fmt.Errorf("datauri: invalid encoding %s", b.du.Encoding)
+	}
+	du := b.du
+	du.Params = params
+	return &du, nil
+}
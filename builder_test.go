@@ -0,0 +1,70 @@
+package datauri
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		b        *Builder
+		expected string
+	}{
+		{NewBuilder(), "data:text/plain;base64,"},
+		{NewBuilder().Data([]byte("hey")), "data:text/plain;base64,aGV5"},
+		{NewBuilder().MediaType("image", "png").Param("name", "logo").Data([]byte("hey")), "data:image/png;name=logo;base64,aGV5"},
+		{NewBuilder().Param("charset", "utf-8").Encoding(EncodingASCII).Data([]byte("a b")), "data:text/plain;charset=utf-8,a%20b"},
+	}
+	for _, test := range tests {
+		du, err := test.b.Build()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := du.String(); got != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, got)
+		}
+	}
+
+	invalid := []*Builder{
+		NewBuilder().MediaType("image", ""),
+		NewBuilder().MediaType("image/png", "x"),
+		NewBuilder().Param("a b", "c"),
+		NewBuilder().Encoding("base32"),
+	}
+	for _, b := range invalid {
+		if du, err := b.Build(); err == nil {
+			t.Errorf("Expected an error, got %s", du)
+		}
+	}
+}
+
+func TestBuilderReuse(t *testing.T) {
+	b := NewBuilder().MediaType("image", "png").Param("name", "a")
+	du1, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	du2, err := b.Param("name", "b").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if du1.Params["name"] != "a" || du2.Params["name"] != "b" {
+		t.Errorf("Expected a and b, got %s and %s", du1.Params["name"], du2.Params["name"])
+	}
+}
+
+func ExampleBuilder() {
+	du, err := NewBuilder().
+		MediaType("image", "png").
+		Param("name", "logo").
+		Encoding(EncodingBase64).
+		Data([]byte("heya")).
+		Build()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(du)
+	// Output: data:image/png;name=logo;base64,aGV5YQ==
+}
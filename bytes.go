@@ -0,0 +1,87 @@
+package datauri
+
+import "encoding/json"
+
+// Bytes is a payload with its media type, marshaled as a Data URI, for
+// the fields of structs which only care about the bytes, like:
+//
+//	type Profile struct {
+//		Logo datauri.Bytes `json:"logo"`
+//	}
+//
+// The zero Bytes is marshaled as an empty text, or null in JSON.
+type Bytes struct {
+	// Data is the payload.
+	Data []byte
+	// ContentType is the media type of Data, which may hold
+	// parameters, e.g. "text/plain;charset=utf-8". If empty,
+	// it is detected from Data when marshaling.
+	ContentType string
+}
+
+// IsZero reports whether b has neither data nor media type.
+func (b Bytes) IsZero() bool {
+	return len(b.Data) == 0 && b.ContentType == ""
+}
+
+// DataURI returns b as a DataURI, with base64 encoding.
+func (b Bytes) DataURI() (*DataURI, error) {
+	if b.ContentType == "" {
+		return newDetected(b.Data, ""), nil
+	}
+	return NewChecked(b.Data, b.ContentType)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface,
+// writing b as a Data URI, or an empty text for the zero Bytes.
+func (b Bytes) MarshalText() ([]byte, error) {
+	if b.IsZero() {
+		return []byte{}, nil
+	}
+	du, err := b.DataURI()
+	if err != nil {
+		return nil, err
+	}
+	return du.MarshalText()
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface,
+// decoding a Data URI into b. An empty text sets b to the zero Bytes.
+func (b *Bytes) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*b = Bytes{}
+		return nil
+	}
+	du, err := DecodeBytes(text)
+	if err != nil {
+		return err
+	}
+	*b = Bytes{Data: du.Data, ContentType: cleanContentType(contentTypeHeader(&du.MediaType))}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, writing b as
+// a JSON string, or null for the zero Bytes.
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	if b.IsZero() {
+		return []byte("null"), nil
+	}
+	txt, err := b.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(txt))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, reading
+// a JSON string as a Data URI. As usual, null leaves b unmodified.
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return b.UnmarshalText([]byte(s))
+}
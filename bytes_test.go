@@ -0,0 +1,54 @@
+package datauri
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBytes(t *testing.T) {
+	type profile struct {
+		Logo  Bytes  `json:"logo"`
+		Notes Bytes  `json:"notes"`
+		Empty Bytes  `json:"empty"`
+		Ptr   *Bytes `json:"ptr,omitempty"`
+	}
+	p := profile{
+		Logo:  Bytes{Data: []byte("\x89PNG\x0d\x0a\x1a\x0a")},
+		Notes: Bytes{Data: []byte("heya"), ContentType: "text/plain;charset=utf-8"},
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"logo":"data:image/png;base64,iVBORw0KGgo=","notes":"data:text/plain;charset=utf-8;base64,aGV5YQ==","empty":null}`
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, b)
+	}
+
+	var got profile
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Logo.Data) != string(p.Logo.Data) || got.Logo.ContentType != "image/png" {
+		t.Errorf("Expected %v, got %v", p.Logo, got.Logo)
+	}
+	if string(got.Notes.Data) != "heya" || got.Notes.ContentType != "text/plain;charset=utf-8" {
+		t.Errorf("Expected %v, got %v", p.Notes, got.Notes)
+	}
+	if !got.Empty.IsZero() {
+		t.Errorf("Expected the zero Bytes, got %v", got.Empty)
+	}
+
+	if err := json.Unmarshal([]byte(`{"logo":"data:,a%20b"}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Logo.Data) != "a b" || got.Logo.ContentType != "text/plain;charset=US-ASCII" {
+		t.Errorf("Expected %s, got %v", "a b", got.Logo)
+	}
+	if err := json.Unmarshal([]byte(`{"logo":"notadatauri"}`), &got); err == nil {
+		t.Error("Expected an error for an invalid Data URI")
+	}
+	if _, err := json.Marshal(Bytes{Data: []byte("a"), ContentType: "invalid"}); err == nil {
+		t.Error("Expected an error for an invalid media type")
+	}
+}
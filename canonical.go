@@ -0,0 +1,54 @@
+package datauri
+
+import (
+	"sort"
+	"strings"
+)
+
+// Canonicalize rewrites du in its canonical form, so that Data URIs
+// holding the same content are written the same way, e.g. for cache
+// keys or deduplication:
+//   - the type, the subtype and the parameter attributes are lowercased,
+//   - the charset is normalized as by NormalizeCharset,
+//   - the payload is encoded in base64.
+//
+// The parameters are always written sorted by String. When attributes
+// only differ by their case, the value of the first one in byte order
+// is kept. A DataURI decoded with WithRoundTrip forgets its source.
+func (du *DataURI) Canonicalize() {
+	du.Type = strings.ToLower(du.Type)
+	du.Subtype = strings.ToLower(du.Subtype)
+	if len(du.Params) > 0 {
+		attrs := make([]string, 0, len(du.Params))
+		for k := range du.Params {
+			attrs = append(attrs, k)
+		}
+		sort.Strings(attrs)
+		params := make(map[string]string, len(attrs))
+		for _, k := range attrs {
+			attr := strings.ToLower(k)
+			if _, ok := params[attr]; ok {
+				continue
+			}
+			v := du.Params[k]
+			if attr == "charset" {
+				v = normalizeCharset(v)
+			}
+			params[attr] = v
+		}
+		du.Params = params
+	}
+	du.Encoding = EncodingBase64
+	forgetOriginal(du)
+}
+
+// CanonicalString decodes the Data URI s and returns it
+// in its canonical form, see DataURI.Canonicalize.
+func CanonicalString(s string) (string, error) {
+	du, err := DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	du.Canonicalize()
+	return du.String(), nil
+}
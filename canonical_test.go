@@ -0,0 +1,45 @@
+package datauri
+
+import (
+	"testing"
+)
+
+func TestCanonicalString(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected string
+	}{
+		{`data:,heya`, `data:text/plain;charset=us-ascii;base64,aGV5YQ==`},
+		{`data:text/plain;charset=US-ASCII;base64,aGV5YQ==`, `data:text/plain;charset=us-ascii;base64,aGV5YQ==`},
+		{`data:text/Plain;Charset=UTF8;Name=a.txt,he%79a`, `data:text/plain;charset=utf-8;name=a.txt;base64,aGV5YQ==`},
+		{`data:text/plain;name="a.txt";charset=latin1;base64,aGV5` + "\n" + `YQ`, `data:text/plain;charset=iso-8859-1;name=a.txt;base64,aGV5YQ==`},
+		{`data:image/PNG;base64,aGV5YQ==`, `data:image/png;base64,aGV5YQ==`},
+	}
+	for _, test := range tests {
+		got, err := CanonicalString(test.Input)
+		if err != nil {
+			t.Errorf("%s: %v", test.Input, err)
+			continue
+		}
+		if got != test.Expected {
+			t.Errorf("%s: expected %s, got %s", test.Input, test.Expected, got)
+		}
+	}
+
+	if _, err := CanonicalString("data:text/plain"); err == nil {
+		t.Errorf("Expected an error, got nil")
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	du, err := DecodeString(`data:text/plain;charset=UTF-8,heya`, WithRoundTrip())
+	if err != nil {
+		t.Fatal(err)
+	}
+	du.Params["Charset"] = "ascii"
+	du.Canonicalize()
+	expected := `data:text/plain;charset=us-ascii;base64,aGV5YQ==`
+	if got := du.String(); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
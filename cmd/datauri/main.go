@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/invopop/datauri"
+)
+
+const usage = `datauri - Encode or decode data URIs
+
+Usage:
+  datauri encode [OPTION]... [FILE]
+  datauri decode [FILE]
+
+  encode prints the data URI of FILE, or of standard input if FILE is - or omitted.
+  Unless -media-type is used, the media type is detected from the content of the data,
+  and from the extension of FILE for generic content, see datauri.DetectWithHints.
+
+  decode prints the payload of the data URI in FILE, or in standard input if FILE
+  is - or omitted.
+
+Examples:
+  datauri encode file.png > uri.txt
+  datauri decode < uri.txt > out.bin
+`
+
+func main() {
+	log.SetFlags(0)
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+
+	var err error
+	switch flag.Arg(0) {
+	case "encode":
+		err = encode(flag.Args()[1:], os.Stdin, os.Stdout)
+	case "decode":
+		err = decode(flag.Args()[1:], os.Stdin, os.Stdout)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// open returns the reader of the FILE argument of args,
+// stdin if it is - or omitted.
+func open(args []string, stdin io.Reader) (io.Reader, string, func(), error) {
+	switch {
+	case len(args) > 1:
+		return nil, "", nil, errors.New("too many arguments")
+	case len(args) == 0 || args[0] == "-":
+		return stdin, "", func() {}, nil
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return f, args[0], func() { f.Close() }, nil //nolint:errcheck
+}
+
+func encode(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("encode", flag.ExitOnError)
+	var (
+		mediaType string
+		ascii     bool
+	)
+	const mediaTypeUsage = "media type of the data, with optional parameters, instead of detecting it"
+	fs.StringVar(&mediaType, "media-type", "", mediaTypeUsage)
+	fs.StringVar(&mediaType, "m", "", mediaTypeUsage)
+	const asciiUsage = "encode data using ascii instead of base64"
+	fs.BoolVar(&ascii, "ascii", false, asciiUsage)
+	fs.BoolVar(&ascii, "a", false, asciiUsage)
+	fs.Parse(args) //nolint:errcheck
+
+	in, filename, closeIn, err := open(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+
+	br := bufio.NewReader(in)
+	if mediaType == "" {
+		// like http.DetectContentType, only the first 512 bytes are considered
+		head, err := br.Peek(512)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		mediaType = datauri.DetectWithHints(head, filename)
+	}
+	du, err := datauri.NewChecked(nil, mediaType)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(stdout)
+	var enc io.WriteCloser
+	if ascii {
+		du.Encoding = datauri.EncodingASCII
+		enc = nopCloser{datauri.NewEscapeWriter(w)}
+	} else {
+		enc = base64.NewEncoder(base64.StdEncoding, w)
+	}
+	// the header, as the payload is empty
+	if _, err := du.WriteTo(w); err != nil {
+		return err
+	}
+	if _, err := io.Copy(enc, br); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func decode(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("decode", flag.ExitOnError)
+	fs.Parse(args) //nolint:errcheck
+
+	in, _, closeIn, err := open(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+
+	r, err := datauri.NewReader(in)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(stdout, r)
+	return err
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -1,3 +1,5 @@
+// Command dataurl encodes or decodes a Data URI with the -d, -m and -a
+// flags of the first releases. The datauri command supersedes it.
 package main
 
 import (
@@ -0,0 +1,71 @@
+// Package compat is a corpus of Data URIs generated by real-world tools,
+// used to check that the default, lenient, decoder of datauri decodes
+// what browsers, CSS tooling and office suites produce.
+//
+// The compatibility matrix below lists the corrections, see
+// datauri.Analyze, each source requires. All of them are made by the
+// default decoder; only datauri.WithStrictRFC2397 rejects them.
+//
+//	Source                                  Corrections
+//	canvas.toDataURL, PNG and JPEG          none
+//	FileReader.readAsDataURL                none
+//	CSS, encodeURIComponent'd SVG           none
+//	CSS, base64 fonts (url-loader)          none
+//	CSS, mini-svg-data-uri                  unescaped_char
+//	CSS, hand-written ";utf8," SVG          bare_flag, unescaped_char
+//	Outlook HTML bodies, CRLF wrapped       line_break
+//	Word HTML exports, LF wrapped           line_break
+package compat
+
+import (
+	"embed"
+
+	"github.com/invopop/datauri"
+)
+
+//go:embed corpus
+var corpus embed.FS
+
+// Sample is a Data URI of the corpus.
+type Sample struct {
+	// Name is the name of the sample in the corpus.
+	Name string
+	// Source describes the tool which generated the sample.
+	Source string
+	// Requires lists the kinds of corrections the sample requires
+	// to be decoded, in order of first occurrence.
+	Requires []datauri.CorrectionKind
+}
+
+// URI returns the Data URI of s.
+func (s Sample) URI() string {
+	b, err := corpus.ReadFile("corpus/" + s.Name + ".txt")
+	if err != nil {
+		panic("compat: no sample " + s.Name)
+	}
+	return string(b)
+}
+
+// Samples is the corpus.
+var Samples = []Sample{
+	{"canvas-png", "canvas.toDataURL()", nil},
+	{"canvas-jpeg", `canvas.toDataURL("image/jpeg")`, nil},
+	{"filereader-text", "FileReader.readAsDataURL, text file", nil},
+	{"filereader-blob-charset", "FileReader.readAsDataURL, Blob with a charset", nil},
+	{"filereader-unknown", "FileReader.readAsDataURL, file of unknown type", nil},
+	{"css-svg-encoded", "CSS, SVG escaped with encodeURIComponent", nil},
+	{"css-font-woff2", "CSS, WOFF2 font inlined by url-loader", nil},
+	{"css-svg-mini", "CSS, SVG escaped with mini-svg-data-uri", []datauri.CorrectionKind{
+		datauri.CorrectionUnescapedChar,
+	}},
+	{"css-svg-utf8", `CSS, hand-written "data:image/svg+xml;utf8," SVG`, []datauri.CorrectionKind{
+		datauri.CorrectionBareFlag,
+		datauri.CorrectionUnescapedChar,
+	}},
+	{"office-outlook-png", "Outlook HTML message body", []datauri.CorrectionKind{
+		datauri.CorrectionLineBreak,
+	}},
+	{"office-word-png", "Word HTML export", []datauri.CorrectionKind{
+		datauri.CorrectionLineBreak,
+	}},
+}
@@ -0,0 +1,52 @@
+package compat
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/invopop/datauri"
+)
+
+func TestSamples(t *testing.T) {
+	for _, s := range Samples {
+		uri := s.URI()
+		du, err := datauri.DecodeString(uri)
+		if err != nil {
+			t.Errorf("%s: %v", s.Name, err)
+			continue
+		}
+		if !du.HasPayload() {
+			t.Errorf("%s: expected a payload", s.Name)
+		}
+
+		r := datauri.Analyze(uri)
+		if got := r.Required(); !reflect.DeepEqual(got, s.Requires) {
+			t.Errorf("%s: expected %v, got %v", s.Name, s.Requires, got)
+		}
+		if len(s.Requires) > 0 && !errors.Is(r.StrictErr, datauri.ErrNotConformant) {
+			t.Errorf("%s: expected %v, got %v", s.Name, datauri.ErrNotConformant, r.StrictErr)
+		}
+		if len(s.Requires) == 0 && r.StrictErr != nil {
+			t.Errorf("%s: expected no strict error, got %v", s.Name, r.StrictErr)
+		}
+	}
+}
+
+func TestCorpusCoverage(t *testing.T) {
+	names := make(map[string]bool)
+	for _, s := range Samples {
+		names[s.Name] = true
+	}
+	entries, err := fs.ReadDir(corpus, "corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if name := strings.TrimSuffix(e.Name(), ".txt"); !names[name] {
+			t.Errorf("Expected a sample for %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package datauri
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AppendData appends data to the payload of du.
+func (du *DataURI) AppendData(data []byte) {
+	du.Data = append(du.Data, data...)
+}
+
+// Concat returns a new DataURI whose payload is the concatenation of the
+// payloads of dus, in order.
+//
+// All the DataURIs must share the same media type and parameters,
+// otherwise an error is returned. The resulting DataURI uses the media
+// type and encoding of the first element.
+func Concat(dus ...*DataURI) (*DataURI, error) {
+	if len(dus) == 0 {
+		return nil, errors.New("datauri: nothing to concatenate")
+	}
+	first := dus[0]
+	size := 0
+	for i, du := range dus {
+		if du == nil {
+			return nil, fmt.Errorf("datauri: nil DataURI at index %d", i)
+		}
+		if !compatibleMediaTypes(&first.MediaType, &du.MediaType) {
+			return nil, fmt.Errorf("datauri: incompatible media type %s at index %d, expected %s",
+				du.MediaType.String(), i, first.MediaType.String())
+		}
+		size += len(du.Data)
+	}
+
+	params := make(map[string]string, len(first.Params))
+	for k, v := range first.Params {
+		params[k] = v
+	}
+	data := make([]byte, 0, size)
+	for _, du := range dus {
+		data = append(data, du.Data...)
+	}
+	return &DataURI{
+		MediaType: MediaType{
+			Type:    first.Type,
+			Subtype: first.Subtype,
+			Params:  params,
+		},
+		Encoding: first.Encoding,
+		Data:     data,
+	}, nil
+}
+
+// compatibleMediaTypes reports whether payloads described by a and b
+// can be joined together. Type, subtype and attribute names are compared
+// case-insensitively, parameter values exactly.
+func compatibleMediaTypes(a, b *MediaType) bool {
+	if !strings.EqualFold(a.Type, b.Type) || !strings.EqualFold(a.Subtype, b.Subtype) {
+		return false
+	}
+	if len(a.Params) != len(b.Params) {
+		return false
+	}
+	for k, v := range a.Params {
+		found := false
+		for bk, bv := range b.Params {
+			if strings.EqualFold(k, bk) {
+				if v != bv {
+					return false
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package datauri
+
+import (
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	tests := []struct {
+		Inputs       []*DataURI
+		ExpectedErr  bool
+		ExpectedData string
+	}{
+		{
+			[]*DataURI{
+				New([]byte("hel"), "text/plain", "charset", "utf-8"),
+				New([]byte("lo"), "text/plain", "charset", "utf-8"),
+				New([]byte(" world"), "TEXT/plain", "charset", "utf-8"),
+			},
+			false,
+			"hello world",
+		},
+		{
+			[]*DataURI{
+				New([]byte("hel"), "text/plain", "charset", "utf-8"),
+			},
+			false,
+			"hel",
+		},
+		{
+			[]*DataURI{},
+			true,
+			"",
+		},
+		{
+			[]*DataURI{
+				New([]byte("hel"), "text/plain"),
+				New([]byte("lo"), "text/html"),
+			},
+			true,
+			"",
+		},
+		{
+			[]*DataURI{
+				New([]byte("hel"), "text/plain", "charset", "utf-8"),
+				New([]byte("lo"), "text/plain", "charset", "iso-8859-1"),
+			},
+			true,
+			"",
+		},
+		{
+			[]*DataURI{
+				New([]byte("hel"), "text/plain"),
+				nil,
+			},
+			true,
+			"",
+		},
+	}
+	for _, test := range tests {
+		du, err := Concat(test.Inputs...)
+		if test.ExpectedErr {
+			if err == nil {
+				t.Errorf("Expected error, got %v", du)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(du.Data) != test.ExpectedData {
+			t.Errorf("Expected %q, got %q", test.ExpectedData, du.Data)
+		}
+	}
+}
+
+func TestConcatDoesNotAlias(t *testing.T) {
+	a := New([]byte("abc"), "text/plain")
+	b := New([]byte("def"), "text/plain")
+	du, err := Concat(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	du.Data[0] = 'X'
+	du.Params["foo"] = "bar"
+	if string(a.Data) != "abc" {
+		t.Errorf("Expected first input to be untouched, got %q", a.Data)
+	}
+	if _, ok := a.Params["foo"]; ok {
+		t.Error("Expected first input params to be untouched")
+	}
+}
+
+func TestAppendData(t *testing.T) {
+	du := New([]byte("abc"), "text/plain")
+	du.AppendData([]byte("def"))
+	du.AppendData(nil)
+	if string(du.Data) != "abcdef" {
+		t.Errorf("Expected %q, got %q", "abcdef", du.Data)
+	}
+}
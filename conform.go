@@ -0,0 +1,62 @@
+package datauri
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Profile is a set of rules a Data URI must follow to conform, see Conforms.
+type Profile int
+
+// Profiles of Conforms.
+const (
+	// ProfileRFC2397 only accepts the Data URIs conforming to RFC 2397.
+	ProfileRFC2397 Profile = iota
+	// ProfileLenient accepts the Data URIs decoded by DecodeString,
+	// with its default options.
+	ProfileLenient
+)
+
+// Deviation describes how a Data URI deviates from RFC 2397.
+type Deviation struct {
+	// Offset is the position of the deviation in the input,
+	// or -1 if unknown.
+	Offset int
+	// Kind is the kind of correction the decoder makes for
+	// the deviation, or an empty string if it can't be corrected.
+	Kind CorrectionKind
+	// Message describes the deviation.
+	Message string
+}
+
+// Fatal reports whether d makes the Data URI undecodable.
+func (d Deviation) Fatal() bool {
+	return d.Kind == ""
+}
+
+// Conforms reports whether the Data URI s conforms to profile,
+// and returns its deviations from RFC 2397, whatever the profile.
+// It uses the same engine as DecodeString, so that systems validating
+// Data URIs agree with the decoder.
+func Conforms(s string, profile Profile) (bool, []Deviation) {
+	var corrections []Correction
+	_, err := DecodeString(s, WithCorrections(&corrections))
+	if err != nil {
+		d := Deviation{Offset: -1, Message: err.Error()}
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			d.Offset = parseErr.Offset
+		}
+		return false, []Deviation{d}
+	}
+
+	deviations := make([]Deviation, len(corrections))
+	for i, c := range corrections {
+		deviations[i] = Deviation{
+			Offset:  c.Offset,
+			Kind:    c.Kind,
+			Message: fmt.Sprintf("%s %q", c.Kind, c.Original),
+		}
+	}
+	return profile == ProfileLenient || len(deviations) == 0, deviations
+}
@@ -0,0 +1,50 @@
+package datauri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConforms(t *testing.T) {
+	tests := []struct {
+		Input              string
+		ExpectedRFC2397    bool
+		ExpectedLenient    bool
+		ExpectedDeviations []Deviation
+	}{
+		{`data:text/plain;charset=utf-8;base64,aGV5YQ==`, true, true, []Deviation{}},
+		{
+			`data:text/plain;utf8,heya`, false, true,
+			[]Deviation{{Offset: 16, Kind: CorrectionBareFlag, Message: `bare_flag "utf8"`}},
+		},
+		{
+			`data:,A%2`, false, false,
+			[]Deviation{{Offset: 7, Message: `invalid URL escape "%2" at offset 7`}},
+		},
+		{
+			`data:;base64,aGV5Y!==`, false, false,
+			[]Deviation{{Offset: 18, Message: `illegal base64 data at input byte 18`}},
+		},
+		{
+			`data:text/plain`, false, false,
+			[]Deviation{{Offset: 15, Message: `incomplete media type at offset 15`}},
+		},
+	}
+	for _, test := range tests {
+		ok, deviations := Conforms(test.Input, ProfileRFC2397)
+		if ok != test.ExpectedRFC2397 {
+			t.Errorf("%s: expected %v with ProfileRFC2397", test.Input, test.ExpectedRFC2397)
+		}
+		if !reflect.DeepEqual(deviations, test.ExpectedDeviations) {
+			t.Errorf("%s: expected %v, got %v", test.Input, test.ExpectedDeviations, deviations)
+		}
+		if ok, _ := Conforms(test.Input, ProfileLenient); ok != test.ExpectedLenient {
+			t.Errorf("%s: expected %v with ProfileLenient", test.Input, test.ExpectedLenient)
+		}
+		for _, d := range deviations {
+			if d.Fatal() == test.ExpectedLenient {
+				t.Errorf("%s: unexpected fatal deviation %v", test.Input, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !datauri_unsafe
+
+package datauri
+
+// stringToBytes returns s as a byte slice, which must not be modified.
+// Built with the datauri_unsafe tag, the memory of s is shared.
+func stringToBytes(s string) []byte {
+	return []byte(s)
+}
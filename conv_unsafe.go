@@ -0,0 +1,9 @@
+//go:build datauri_unsafe
+
+package datauri
+
+import "unsafe"
+
+func stringToBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
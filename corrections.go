@@ -0,0 +1,77 @@
+package datauri
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotConformant is returned, wrapped, when decoding with
+// WithStrictRFC2397 an input that needs a correction.
+var ErrNotConformant = errors.New("datauri: not conformant to RFC 2397")
+
+// CorrectionKind identifies the kind of deviation from RFC 2397
+// fixed by the decoder.
+type CorrectionKind string
+
+// Kinds of the corrections done by the decoder.
+const (
+	// CorrectionBareFlag is a bare parameter flag, such as ";utf8",
+	// replaced with its attribute=value equivalent, see RegisterFlag.
+	CorrectionBareFlag CorrectionKind = "bare_flag"
+	// CorrectionLineBreak is a line break, "\n", "\r\n" or "\r",
+	// ignored in a base64 payload.
+	CorrectionLineBreak CorrectionKind = "line_break"
+	// CorrectionUnescapedChar is a character not allowed in a URL,
+	// such as a space or a quote, read as is in an ASCII payload.
+	CorrectionUnescapedChar CorrectionKind = "unescaped_char"
+	// CorrectionURLSafeBase64 is a base64 payload using the URL-safe
+	// alphabet of RFC 4648, with '-' and '_' instead of '+' and '/'.
+	// Only the first such character of a payload is reported.
+	CorrectionURLSafeBase64 CorrectionKind = "url_safe_base64"
+	// CorrectionMissingPadding is a base64 payload missing
+	// its trailing '=' padding.
+	CorrectionMissingPadding CorrectionKind = "missing_padding"
+	// CorrectionTypographicChar is a typographic character in the header,
+	// such as a curly quote, replaced with its ASCII equivalent,
+	// see WithHeaderRepair.
+	CorrectionTypographicChar CorrectionKind = "typographic_char"
+)
+
+// Correction describes a deviation from RFC 2397 silently fixed by
+// the decoder, to let it decode the input anyway.
+type Correction struct {
+	// Offset is the position of the deviation in the input.
+	Offset int
+	Kind   CorrectionKind
+	// Original is the part of the input that was fixed.
+	Original string
+	// Replacement is what Original was read as.
+	Replacement string
+}
+
+// WithCorrections makes the decoder append the corrections it made
+// to the input to *dst, so that they can be audited.
+func WithCorrections(dst *[]Correction) Option {
+	return func(o *options) {
+		o.corrections = dst
+	}
+}
+
+// WithStrictRFC2397 makes the decoder fail with ErrNotConformant
+// instead of doing any correction to the input.
+func WithStrictRFC2397() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// correct records c, or returns an error in strict mode.
+func (o *options) correct(c Correction) error {
+	if o.strict {
+		return fmt.Errorf("%w: %s %q at offset %d", ErrNotConformant, c.Kind, c.Original, c.Offset)
+	}
+	if o.corrections != nil {
+		*o.corrections = append(*o.corrections, c)
+	}
+	return nil
+}
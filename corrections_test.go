@@ -0,0 +1,139 @@
+package datauri
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithCorrections(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected []Correction
+	}{
+		{`data:text/plain;charset=utf-8;base64,aGV5YQ==`, nil},
+		{
+			`data:text/plain;utf8,heya`,
+			[]Correction{
+				{Offset: 16, Kind: CorrectionBareFlag, Original: "utf8", Replacement: "charset=utf-8"},
+			},
+		},
+		{
+			"data:;utf8;base64,aGV5\nYQ==\n",
+			[]Correction{
+				{Offset: 6, Kind: CorrectionBareFlag, Original: "utf8", Replacement: "charset=utf-8"},
+				{Offset: 22, Kind: CorrectionLineBreak, Original: "\n"},
+				{Offset: 27, Kind: CorrectionLineBreak, Original: "\n"},
+			},
+		},
+		{
+			"data:;base64,aGV5\r\nYQ==\r",
+			[]Correction{
+				{Offset: 17, Kind: CorrectionLineBreak, Original: "\r\n"},
+				{Offset: 23, Kind: CorrectionLineBreak, Original: "\r"},
+			},
+		},
+		{
+			"data:;base64,aGV5\r\nYQ\r\n",
+			[]Correction{
+				{Offset: 17, Kind: CorrectionLineBreak, Original: "\r\n"},
+				{Offset: 21, Kind: CorrectionLineBreak, Original: "\r\n"},
+				{Offset: 21, Kind: CorrectionMissingPadding, Replacement: "=="},
+			},
+		},
+		{
+			`data:;base64,-_-_`,
+			[]Correction{
+				{Offset: 13, Kind: CorrectionURLSafeBase64, Original: "-", Replacement: "+"},
+			},
+		},
+		{
+			`data:image/svg+xml,<svg width='1'/>`,
+			[]Correction{
+				{Offset: 19, Kind: CorrectionUnescapedChar, Original: "<", Replacement: "%3C"},
+				{Offset: 23, Kind: CorrectionUnescapedChar, Original: " ", Replacement: "%20"},
+				{Offset: 34, Kind: CorrectionUnescapedChar, Original: ">", Replacement: "%3E"},
+			},
+		},
+	}
+	for _, test := range tests {
+		var corrections []Correction
+		if _, err := DecodeString(test.Input, WithCorrections(&corrections)); err != nil {
+			t.Error(err)
+			continue
+		}
+		if !reflect.DeepEqual(corrections, test.Expected) {
+			t.Errorf("%q: expected %v, got %v", test.Input, test.Expected, corrections)
+		}
+		for _, c := range corrections {
+			if got := test.Input[c.Offset : c.Offset+len(c.Original)]; got != c.Original {
+				t.Errorf("%q: expected %q at offset %d, got %q", test.Input, c.Original, c.Offset, got)
+			}
+		}
+	}
+}
+
+func TestHeaderRepair(t *testing.T) {
+	tests := []struct {
+		Input       string
+		Expected    string
+		Corrections []Correction
+	}{
+		{
+			"data:text/plain;name=“a,b”,hey",
+			"data:text/plain;name=a%2Cb,hey",
+			[]Correction{
+				{Offset: 21, Kind: CorrectionTypographicChar, Original: "“", Replacement: `"`},
+				{Offset: 27, Kind: CorrectionTypographicChar, Original: "”", Replacement: `"`},
+			},
+		},
+		{
+			"data:text/plain; charset=utf-8,a b",
+			"data:text/plain;charset=utf-8,a%20b",
+			[]Correction{
+				{Offset: 16, Kind: CorrectionTypographicChar, Original: " ", Replacement: ""},
+				{Offset: 33, Kind: CorrectionUnescapedChar, Original: " ", Replacement: "%20"},
+			},
+		},
+		{
+			"data:application/x\x96custom;name=\x93a\x94;base64,aGV5",
+			"data:application/x-custom;name=a;base64,aGV5",
+			[]Correction{
+				{Offset: 18, Kind: CorrectionTypographicChar, Original: "\x96", Replacement: "-"},
+				{Offset: 31, Kind: CorrectionTypographicChar, Original: "\x93", Replacement: `"`},
+				{Offset: 33, Kind: CorrectionTypographicChar, Original: "\x94", Replacement: `"`},
+			},
+		},
+	}
+	for _, test := range tests {
+		if _, err := DecodeString(test.Input); err == nil && test.Corrections != nil {
+			t.Errorf("%q: expected an error without repair", test.Input)
+		}
+		var corrections []Correction
+		du, err := DecodeString(test.Input, WithHeaderRepair(), WithCorrections(&corrections))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if du.String() != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, du.String())
+		}
+		if !reflect.DeepEqual(corrections, test.Corrections) {
+			t.Errorf("%q: expected %v, got %v", test.Input, test.Corrections, corrections)
+		}
+	}
+
+	// the payload is left as is
+	du, err := DecodeString("data:,“hey”", WithHeaderRepair())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(du.Data) != "“hey”" {
+		t.Errorf("Expected %s, got %s", "“hey”", du.Data)
+	}
+
+	_, err = DecodeString("data:text/plain;name=“a”,", WithHeaderRepair(), WithStrictRFC2397())
+	if !errors.Is(err, ErrNotConformant) {
+		t.Errorf("Expected %v, got %v", ErrNotConformant, err)
+	}
+}
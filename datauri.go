@@ -1,15 +1,17 @@
 package datauri
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"mime"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -19,6 +21,22 @@ const (
 	EncodingASCII = "ascii"
 )
 
+// ErrMissingComma is returned when decoding a Data URI which ends before
+// the comma separating its header from its payload. A Data URI with an
+// explicitly empty payload, like "data:,", is valid.
+var ErrMissingComma = errors.New("missing comma before data")
+
+// Categories of the *ParseError returned when decoding an invalid Data URI.
+var (
+	// ErrInvalidMediaType is the category of the errors in a media type.
+	ErrInvalidMediaType = errors.New("invalid media type")
+	// ErrInvalidParam is the category of the errors in a parameter,
+	// including the base64 encoding.
+	ErrInvalidParam = errors.New("invalid parameter")
+	// ErrInvalidData is the category of the errors in a payload.
+	ErrInvalidData = errors.New("invalid data")
+)
+
 func defaultMediaType() MediaType {
 	return MediaType{
 		"text",
@@ -44,6 +62,10 @@ func (mt *MediaType) ContentType() string {
 //
 // Params values are escaped with the Escape function, rather than in a quoted string.
 func (mt *MediaType) String() string {
+	return mt.ContentType() + mt.paramsString()
+}
+
+func (mt *MediaType) paramsString() string {
 	var (
 		buf  bytes.Buffer
 		keys = make([]string, len(mt.Params))
@@ -58,7 +80,7 @@ func (mt *MediaType) String() string {
 		v := mt.Params[k]
 		fmt.Fprintf(&buf, ";%s=%s", k, EscapeString(v))
 	}
-	return mt.ContentType() + (&buf).String()
+	return (&buf).String()
 }
 
 // DataURI is the combination of a MediaType describing the type of its Data.
@@ -101,6 +123,69 @@ func New(data []byte, mediatype string, paramPairs ...string) *DataURI {
 	}
 }
 
+// NewASCII is like New, but the DataURI is initialized with ASCII
+// encoding: the payload is percent-encoded, which is shorter than base64
+// and human-readable for short text payloads, like "data:,A%20brief%20note".
+func NewASCII(data []byte, mediatype string, paramPairs ...string) *DataURI {
+	du := New(data, mediatype, paramPairs...)
+	du.Encoding = EncodingASCII
+	return du
+}
+
+// NewChecked is like New, but returns an error instead of panicking
+// when mediatype is not of the form "type/subtype" or paramPairs
+// has an odd number of elements, for media types coming from user input.
+// mediatype may also hold parameters, like "text/plain;charset=utf-8",
+// which paramPairs override.
+func NewChecked(data []byte, mediatype string, paramPairs ...string) (*DataURI, error) {
+	base, rest, hasParams := strings.Cut(mediatype, ";")
+	t, st, ok := strings.Cut(strings.TrimSpace(base), "/")
+	if !ok || !isToken(t) || !isToken(st) {
+		return nil, fmt.Errorf("datauri: invalid mediatype %q", mediatype)
+	}
+	if len(paramPairs)%2 != 0 {
+		return nil, errors.New("datauri: requires an even number of param pairs")
+	}
+
+	params := make(map[string]string)
+	if hasParams {
+		_, parsed, err := mime.ParseMediaType("a/b;" + rest)
+		if err != nil {
+			return nil, fmt.Errorf("datauri: invalid mediatype %q: %w", mediatype, err)
+		}
+		for k, v := range parsed {
+			params[k] = v
+		}
+	}
+	for i := 0; i < len(paramPairs); i += 2 {
+		if !isToken(paramPairs[i]) {
+			return nil, fmt.Errorf("datauri: invalid param attribute %q", paramPairs[i])
+		}
+		params[paramPairs[i]] = paramPairs[i+1]
+	}
+	return &DataURI{
+		MediaType: MediaType{
+			Type:    t,
+			Subtype: st,
+			Params:  params,
+		},
+		Encoding: EncodingBase64,
+		Data:     data,
+	}, nil
+}
+
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !isTokenRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
 // String implements the Stringer interface.
 //
 // Note: it doesn't guarantee the returned string is equal to
@@ -108,20 +193,46 @@ func New(data []byte, mediatype string, paramPairs ...string) *DataURI {
 // The reasons for that are:
 //   - Insertion of default values for MediaType that were maybe not in the initial string,
 //   - Various ways to encode the MediaType parameters (quoted string or uri encoded string, the latter is used),
+//
+// Decode with the WithRoundTrip option to get the initial string back.
 func (du *DataURI) String() string {
+	return du.StringWith()
+}
+
+// StringWith returns du as a Data URI string, written with opts.
+func (du *DataURI) StringWith(opts ...EncodeOption) string {
 	var buf bytes.Buffer
-	_, _ = du.WriteTo(&buf)
+	_, _ = du.WriteToWith(&buf, opts...)
 	return (&buf).String()
 }
 
 // WriteTo implements the WriterTo interface.
 // See the note about String().
+//
+// Nothing is written for the zero DataURI.
 func (du *DataURI) WriteTo(w io.Writer) (n int64, err error) {
+	return du.WriteToWith(w)
+}
+
+// WriteToWith writes du to w as a Data URI, with opts.
+//
+// A DataURI with an empty media type is written without it,
+// like "data:;base64,aGV5YQ==", unless AlwaysEmitMediaType is set.
+func (du *DataURI) WriteToWith(w io.Writer, opts ...EncodeOption) (n int64, err error) {
 	var ni int
+	if du.IsZero() {
+		return 0, nil
+	}
+	if orig := originalOf(du); orig != nil && len(opts) == 0 && orig.matches(du) {
+		ni, err = io.WriteString(w, orig.s)
+		return int64(ni), err
+	}
+	eo := newEncodeOptions(opts)
+
 	ni, _ = fmt.Fprint(w, "data:")
 	n += int64(ni)
 
-	ni, _ = fmt.Fprint(w, du.MediaType.String())
+	ni, _ = fmt.Fprint(w, du.mediaTypeString(eo))
 	n += int64(ni)
 
 	if du.Encoding == EncodingBase64 {
@@ -132,15 +243,23 @@ func (du *DataURI) WriteTo(w io.Writer) (n int64, err error) {
 	ni, _ = fmt.Fprint(w, ",")
 	n += int64(ni)
 
+	data := du.Data
+	if eo.stripMetadata {
+		data = stripMetadata(data)
+	}
 	switch du.Encoding {
 	case EncodingBase64:
 		encoder := base64.NewEncoder(base64.StdEncoding, w)
-		if _, err = encoder.Write(du.Data); err != nil {
+		if _, err = encoder.Write(data); err != nil {
 			return
 		}
 		encoder.Close() //nolint:errcheck
 	case EncodingASCII:
-		ni, _ = fmt.Fprint(w, Escape(du.Data))
+		if eo.version >= Version2 {
+			ni, _ = w.Write(appendEscapeV2(nil, data))
+		} else {
+			ni, _ = fmt.Fprint(w, Escape(data))
+		}
 		n += int64(ni)
 	default:
 		err = fmt.Errorf("datauri: invalid encoding %s", du.Encoding)
@@ -150,9 +269,85 @@ func (du *DataURI) WriteTo(w io.Writer) (n int64, err error) {
 	return
 }
 
-// UnmarshalText decodes a Data URI string and sets it to *du
+// AppendText appends du, written as by String, to dst and returns
+// the extended buffer, so that buffers can be reused between calls.
+// Nothing is appended for the zero DataURI.
+func (du *DataURI) AppendText(dst []byte) ([]byte, error) {
+	if du.IsZero() {
+		return dst, nil
+	}
+	if orig := originalOf(du); orig != nil && orig.matches(du) {
+		return append(dst, orig.s...), nil
+	}
+	switch du.Encoding {
+	case EncodingBase64:
+		dst = append(dst, du.header()...)
+		return base64.StdEncoding.AppendEncode(dst, du.Data), nil
+	case EncodingASCII:
+		dst = append(dst, du.header()...)
+		return AppendEscape(dst, du.Data), nil
+	}
+	return dst, fmt.Errorf("datauri: invalid encoding %s", du.Encoding)
+}
+
+// header returns the header of du, up to and including the data comma,
+// as written by WriteTo.
+func (du *DataURI) header() string {
+	h := "data:" + du.mediaTypeString(newEncodeOptions(nil))
+	if du.Encoding == EncodingBase64 {
+		h += ";base64"
+	}
+	return h + ","
+}
+
+func (du *DataURI) mediaTypeString(eo *encodeOptions) string {
+	mt := withDefaultParams(du.MediaType)
+	omitted := mt.Type == "" && mt.Subtype == ""
+	if eo.version >= Version2 {
+		mt, omitted = mediaTypeV2(mt)
+	}
+	if omitted && eo.alwaysEmitMediaType {
+		if mt.Type == "" {
+			mt.Type, mt.Subtype = "text", "plain"
+		}
+		omitted = false
+	}
+	if eo.alwaysEmitCharset && (omitted || strings.EqualFold(mt.Type, "text")) && mt.Params["charset"] == "" {
+		params := make(map[string]string, len(mt.Params)+1)
+		for k, v := range mt.Params {
+			params[k] = v
+		}
+		params["charset"] = "US-ASCII"
+		mt.Params = params
+	}
+	if omitted {
+		return mt.paramsString()
+	}
+	return mt.String()
+}
+
+// IsZero reports whether du is nil or the zero DataURI.
+// The zero DataURI is written as an empty string.
+func (du *DataURI) IsZero() bool {
+	return du == nil || du.Type == "" && du.Subtype == "" && len(du.Params) == 0 &&
+		du.Encoding == "" && len(du.Data) == 0
+}
+
+// HasPayload reports whether du holds payload data.
+// It is false for a Data URI with an explicitly empty payload, like "data:,";
+// Data URIs with no payload section at all fail to decode with ErrMissingComma.
+func (du *DataURI) HasPayload() bool {
+	return len(du.Data) > 0
+}
+
+// UnmarshalText decodes a Data URI string and sets it to *du.
+// An empty text sets *du to the zero DataURI.
 func (du *DataURI) UnmarshalText(text []byte) error {
-	decoded, err := DecodeString(string(text))
+	if len(text) == 0 {
+		*du = DataURI{}
+		return nil
+	}
+	decoded, err := DecodeBytes(text)
 	if err != nil {
 		return err
 	}
@@ -160,107 +355,358 @@ func (du *DataURI) UnmarshalText(text []byte) error {
 	return nil
 }
 
-// MarshalText writes du as a Data URI
+// MarshalText writes du as a Data URI,
+// or an empty text for the zero DataURI.
 func (du *DataURI) MarshalText() ([]byte, error) {
-	buf := bytes.NewBuffer(nil)
-	if _, err := du.WriteTo(buf); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+	return du.AppendText(nil)
 }
 
-type encodedDataReader func(string) ([]byte, error)
+type parser[T input] struct {
+	du              *DataURI
+	l               *lexer[T]
+	opts            *options
+	offset          int // of the current item in the input
+	headerShift     int // removed from the header by WithHeaderRepair
+	currentAttr     string
+	unquoteParamVal bool
+	defaultCharset  string // replaced by the media type of the input
+	base64Enc       *base64.Encoding
+}
 
-var asciiDataReader encodedDataReader = func(s string) ([]byte, error) {
-	us, err := Unescape(s)
-	if err != nil {
-		return nil, err
+func (p *parser[T]) parse() error {
+	parseItem := p.parseItem
+	if p.opts.timing != nil {
+		start := time.Now()
+		parseItem = func(item lexItem[T]) error {
+			return p.timeItem(item, start)
+		}
 	}
-	return []byte(us), nil
+	for {
+		item, ok := p.l.nextItem()
+		if !ok {
+			break
+		}
+		if err := parseItem(item); err != nil {
+			return err
+		}
+		if item.t == itemEOF {
+			return nil
+		}
+		p.offset += len(item.val)
+	}
+	// the lexer stopped without an EOF or an error item
+	return newParseError(p.l.input, len(p.l.input), ErrMissingComma, "unexpected end of input", nil)
 }
 
-var base64DataReader encodedDataReader = func(s string) ([]byte, error) {
-	data, err := base64.StdEncoding.DecodeString(s)
-	if err != nil {
-		return nil, err
-	}
-	return []byte(data), nil
-}
-
-type parser struct {
-	du                  *DataURI
-	l                   *lexer
-	currentAttr         string
-	unquoteParamVal     bool
-	encodedDataReaderFn encodedDataReader
-}
-
-func (p *parser) parse() error {
-	for item := range p.l.items {
-		switch item.t {
-		case itemError:
-			return errors.New(item.String())
-		case itemMediaType:
-			p.du.Type = item.val
-			// Should we clear the default
-			// "charset" parameter at this point?
-			delete(p.du.Params, "charset")
-		case itemMediaSubType:
-			p.du.Subtype = item.val
-		case itemParamAttr:
-			p.currentAttr = item.val
-		case itemLeftStringQuote:
-			p.unquoteParamVal = true
-		case itemParamVal:
-			val := item.val
-			if p.unquoteParamVal {
-				p.unquoteParamVal = false
-				us, err := strconv.Unquote("\"" + val + "\"")
-				if err != nil {
-					return err
-				}
-				val = us
-			} else {
-				us, err := UnescapeToString(val)
-				if err != nil {
-					return err
-				}
-				val = us
-			}
-			p.du.Params[p.currentAttr] = val
-		case itemBase64Enc:
-			p.du.Encoding = EncodingBase64
-			p.encodedDataReaderFn = base64DataReader
-		case itemDataComma:
-			if p.encodedDataReaderFn == nil {
-				p.encodedDataReaderFn = asciiDataReader
+// parseItem parses item. The values of the header items are copied,
+// so that du never shares the memory of a []byte input.
+func (p *parser[T]) parseItem(item lexItem[T]) error {
+	switch item.t {
+	case itemError:
+		return p.l.err
+	case itemMediaType:
+		// media types are case-insensitive
+		p.du.Type = strings.ToLower(string(item.val))
+		// the default media type is replaced, with its parameters,
+		// its charset is handled at the data comma
+		p.defaultCharset = p.du.Params["charset"]
+		clear(p.du.Params)
+	case itemMediaSubType:
+		p.du.Subtype = strings.ToLower(string(item.val))
+	case itemParamAttr:
+		p.currentAttr = string(item.val)
+	case itemLeftStringQuote:
+		p.unquoteParamVal = true
+	case itemParamVal:
+		val := string(item.val)
+		if p.unquoteParamVal {
+			p.unquoteParamVal = false
+			us, err := strconv.Unquote("\"" + val + "\"")
+			if err != nil {
+				return newParseError(p.l.input, p.offset, ErrInvalidParam, "invalid quoted parameter value", nil)
 			}
-		case itemData:
-			reader, err := p.encodedDataReaderFn(item.val)
+			val = us
+		} else {
+			us, err := UnescapeToString(val)
 			if err != nil {
+				return p.offsetError(ErrInvalidParam, err)
+			}
+			val = us
+		}
+		p.du.Params[p.currentAttr] = val
+	case itemParamFlag:
+		flag := string(item.val)
+		attr, val, ok := lookupFlag(flag)
+		if !ok {
+			return newParseError(p.l.input, p.offset, ErrInvalidParam, fmt.Sprintf("expected base64, got %s", flag), nil)
+		}
+		p.du.Params[attr] = val
+		if err := p.opts.correct(Correction{
+			Offset:      p.offset,
+			Kind:        CorrectionBareFlag,
+			Original:    flag,
+			Replacement: attr + "=" + val,
+		}); err != nil {
+			return err
+		}
+	case itemBase64Enc:
+		p.du.Encoding = EncodingBase64
+	case itemDataComma:
+		p.offset += p.headerShift
+		if err := p.applyCharsetPolicy(); err != nil {
+			return err
+		}
+		if err := p.opts.checkMediaType(&p.du.MediaType); err != nil {
+			return err
+		}
+	case itemData:
+		if p.opts.maxDataSize > 0 {
+			if err := p.opts.checkDataSize(payloadSize(item.val, p.du.Encoding)); err != nil {
 				return err
 			}
-			p.du.Data = reader
-		case itemEOF:
-			if p.du.Data == nil {
-				p.du.Data = []byte("")
+		}
+		correct := p.correctUnescapedChars
+		if p.du.Encoding == EncodingBase64 {
+			correct = p.correctLineBreaks
+		}
+		if err := correct(item.val); err != nil {
+			return err
+		}
+		if p.du.Encoding == EncodingBase64 {
+			if err := p.detectBase64Encoding(item.val); err != nil {
+				return err
 			}
-			return nil
 		}
+		if p.opts.validateOnly {
+			if err := p.validateData(item.val); err != nil {
+				return p.offsetError(ErrInvalidData, err)
+			}
+			break
+		}
+		data, err := p.readData(item.val)
+		if err != nil {
+			return p.offsetError(ErrInvalidData, err)
+		}
+		p.du.Data = data
+	case itemEOF:
+		if p.du.Data == nil {
+			p.du.Data = []byte("")
+		}
+	}
+	return nil
+}
+
+// applyCharsetPolicy handles the charset dropped with the default
+// media type, according to the CharsetPolicy.
+func (p *parser[T]) applyCharsetPolicy() error {
+	if p.defaultCharset == "" || p.du.Type != "text" {
+		return nil
+	}
+	if _, ok := lookupParam(&p.du.MediaType, "charset"); ok {
+		return nil
+	}
+	switch p.opts.charset {
+	case CharsetKeep:
+		p.du.Params["charset"] = p.defaultCharset
+	case CharsetRequire:
+		return newParseError(p.l.input, p.offset, ErrInvalidParam, "missing charset parameter", nil)
+	}
+	return nil
+}
+
+// offsetError makes the offset of an *EscapeError or of a
+// base64.CorruptInputError relative to the input, rather than
+// to the current item, and wraps it in a *ParseError of kind.
+func (p *parser[T]) offsetError(kind, err error) error {
+	offset := p.offset
+	var escErr *EscapeError
+	if errors.As(err, &escErr) {
+		escErr.Offset += p.offset
+		offset = escErr.Offset
+	}
+	if ce, ok := err.(base64.CorruptInputError); ok {
+		err = ce + base64.CorruptInputError(p.offset)
+		offset = int(ce) + p.offset
+	}
+	return newParseError(p.l.input, offset, kind, "", err)
+}
+
+// readData decodes the payload s into a new buffer, allocated with
+// the alloc option if set.
+//
+// Both decoders are lenient: the base64 decoder ignores all line breaks,
+// and the characters not allowed in a URL are kept as is. They are
+// reported as corrections by the parser, if asked for.
+func (p *parser[T]) readData(s T) ([]byte, error) {
+	alloc := p.opts.alloc
+	if alloc == nil {
+		alloc = func(n int) []byte { return make([]byte, n) }
+	}
+	if p.du.Encoding == EncodingBase64 {
+		buf := alloc(p.base64Enc.DecodedLen(len(s)))
+		n, err := decodeBase64(p.base64Enc, buf, s)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+	size := len(s) - 2*countByte(s, '%')
+	if size < 0 {
+		// some sequences are incomplete, this is reported below
+		size = 0
+	}
+	return appendUnescape(alloc(size)[:0], s, false)
+}
+
+// detectBase64Encoding sets the encoding of the base64 payload s:
+// the URL-safe alphabet when s holds a '-' or a '_', and no padding
+// when s misses it.
+func (p *parser[T]) detectBase64Encoding(s T) error {
+	urlSafe, err := p.detectURLSafeBase64(s)
+	if err != nil {
+		return err
+	}
+	padded, err := p.detectBase64Padding(s)
+	if err != nil {
+		return err
+	}
+	p.base64Enc = base64Encoding(urlSafe, padded)
+	return nil
+}
+
+func (p *parser[T]) detectURLSafeBase64(s T) (bool, error) {
+	i := indexURLSafeBase64(s)
+	if i < 0 {
+		return false, nil
 	}
-	panic("EOF not found")
+	replacement := "+"
+	if s[i] == '_' {
+		replacement = "/"
+	}
+	return true, p.opts.correct(Correction{
+		Offset:      p.offset + i,
+		Kind:        CorrectionURLSafeBase64,
+		Original:    string(s[i : i+1]),
+		Replacement: replacement,
+	})
+}
+
+func (p *parser[T]) detectBase64Padding(s T) (bool, error) {
+	if isPaddedBase64(s) {
+		// a wrong padding is reported by the decoder
+		return true, nil
+	}
+	s = trimLineBreaks(s)
+	n := len(s) - countByte(s, '\n') - countByte(s, '\r')
+	return false, p.opts.correct(Correction{
+		Offset:      p.offset + len(s),
+		Kind:        CorrectionMissingPadding,
+		Replacement: strings.Repeat("=", 4-n%4),
+	})
+}
+
+func (p *parser[T]) correctLineBreaks(data T) error {
+	if p.opts.corrections == nil && !p.opts.strict {
+		return nil
+	}
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\r' && data[i] != '\n' {
+			continue
+		}
+		lb := string(data[i : i+1])
+		if hasPrefix(data[i:], "\r\n") {
+			lb = "\r\n"
+		}
+		if err := p.opts.correct(Correction{
+			Offset:   p.offset + i,
+			Kind:     CorrectionLineBreak,
+			Original: lb,
+		}); err != nil {
+			return err
+		}
+		i += len(lb) - 1
+	}
+	return nil
+}
+
+func (p *parser[T]) correctUnescapedChars(data T) error {
+	if p.opts.corrections == nil && !p.opts.strict {
+		return nil
+	}
+	for i := 0; i < len(data); i++ {
+		if c := data[i]; !urlCharTable[c] {
+			if err := p.opts.correct(Correction{
+				Offset:      p.offset + i,
+				Kind:        CorrectionUnescapedChar,
+				Original:    string(data[i : i+1]),
+				Replacement: fmt.Sprintf("%%%02X", c),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // DecodeString decodes a Data URI scheme string.
-func DecodeString(s string) (*DataURI, error) {
+func DecodeString(s string, opts ...Option) (*DataURI, error) {
+	return decodeString(s, newOptions(opts))
+}
+
+func decodeString(s string, o *options) (*DataURI, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
 	du := &DataURI{
-		MediaType: defaultMediaType(),
+		MediaType: o.initialMediaType(),
 		Encoding:  EncodingASCII,
 	}
+	src, shift := s, 0
+	if o.repairHeader {
+		var err error
+		if s, shift, err = o.repairHeaderChars(s); err != nil {
+			return nil, err
+		}
+	}
 
-	parser := &parser{
-		du: du,
-		l:  lex(s),
+	parser := &parser[string]{
+		du:          du,
+		l:           lex(s),
+		opts:        o,
+		headerShift: shift,
+	}
+	if err := parser.parse(); err != nil {
+		return nil, err
+	}
+	if o.roundTrip {
+		setOriginal(du, src)
+	}
+	return du, nil
+}
+
+// DecodeBytes is like DecodeString, but decoding the Data URI held in b,
+// which is lexed as is rather than copied into a string first.
+// The returned DataURI does not share the memory of b.
+func DecodeBytes(b []byte, opts ...Option) (*DataURI, error) {
+	return decodeBytes(b, newOptions(opts))
+}
+
+func decodeBytes(b []byte, o *options) (*DataURI, error) {
+	if o.repairHeader || o.roundTrip {
+		// both keep a string of the input
+		return decodeString(string(b), o)
+	}
+	if o.err != nil {
+		return nil, o.err
+	}
+	du := &DataURI{
+		MediaType: o.initialMediaType(),
+		Encoding:  EncodingASCII,
+	}
+	parser := &parser[[]byte]{
+		du:   du,
+		l:    lex(b),
+		opts: o,
 	}
 	if err := parser.parse(); err != nil {
 		return nil, err
@@ -269,22 +715,59 @@ func DecodeString(s string) (*DataURI, error) {
 }
 
 // Decode decodes a Data URI scheme from a io.Reader.
-func Decode(r io.Reader) (*DataURI, error) {
+//
+// With WithAllowedMediaTypes, the header is read first, so that
+// a media type which is not allowed is rejected before the payload
+// is read.
+func Decode(r io.Reader, opts ...Option) (*DataURI, error) {
+	o := newOptions(opts)
+	if o.err != nil {
+		return nil, o.err
+	}
+	if o.rateLimit > 0 {
+		r = newThrottledReader(r, o.rateLimit)
+	}
+	if o.mediaTypes != nil {
+		br := bufio.NewReader(r)
+		header, err := readHeader(br)
+		if err != nil {
+			return nil, err
+		}
+		du, err := DecodeString(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.checkMediaType(&du.MediaType); err != nil {
+			return nil, err
+		}
+		r = io.MultiReader(strings.NewReader(header), br)
+	}
+	if o.maxDataSize > 0 {
+		r = io.LimitReader(r, maxEncodedSize(o.maxDataSize)+1)
+	}
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	return DecodeString(string(data))
+	if o.maxDataSize > 0 && int64(len(data)) > maxEncodedSize(o.maxDataSize) {
+		return nil, o.checkDataSize(o.maxDataSize + 1)
+	}
+	return decodeBytes(data, o)
+}
+
+// AppendEncode appends data encoded into a Data URI, using base 64
+// encoding, to dst and returns the extended buffer. The media type is
+// parsed from mediatype and paramPairs, which must be valid, as for New.
+func AppendEncode(dst, data []byte, mediatype string, paramPairs ...string) []byte {
+	dst, _ = New(data, mediatype, paramPairs...).AppendText(dst)
+	return dst
 }
 
-// EncodeBytes encodes the data bytes into a Data URI string, using base 64 encoding.
+// EncodeBytes encodes the data bytes into a Data URI string, using base 64 encoding,
+// with opts, e.g. WithoutMetadata.
 //
-// The media type of data is detected using http.DetectContentType.
-func EncodeBytes(data []byte) string {
-	mt := http.DetectContentType(data)
-	// http.DetectContentType may add spurious spaces between ; and a parameter.
-	// The canonical way is to not have them.
-	cleanedMt := strings.ReplaceAll(mt, "; ", ";")
-
-	return New(data, cleanedMt).String()
+// The media type of data is detected with the registered Detectors,
+// then http.DetectContentType, see RegisterDetector.
+func EncodeBytes(data []byte, opts ...EncodeOption) string {
+	return newDetected(data, "").StringWith(opts...)
 }
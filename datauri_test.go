@@ -3,11 +3,11 @@ package datauri
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
-	"reflect"
-	"regexp"
 	"strings"
 	"testing"
 )
@@ -167,7 +167,7 @@ func genTestTable() []dataURITest {
 			`data:xxx;base64,aGV5YQ==`,
 			[]item{
 				{itemDataPrefix, dataPrefix},
-				{itemError, "invalid character for media type"},
+				{itemError, "invalid character for media type at offset 8"},
 			},
 			DataURI{},
 		},
@@ -240,28 +240,21 @@ func expectItems(expected, actual []item) bool {
 }
 
 func equal(du1, du2 *DataURI) (bool, error) {
-	if !reflect.DeepEqual(du1.MediaType, du2.MediaType) {
-		return false, nil
-	}
-	if du1.Encoding != du2.Encoding {
-		return false, nil
-	}
-
 	if du1.Data == nil || du2.Data == nil {
 		return false, fmt.Errorf("nil Data")
 	}
-
-	if !bytes.Equal(du1.Data, du2.Data) {
-		return false, nil
-	}
-	return true, nil
+	return du1.Equal(du2), nil
 }
 
 func TestLexDataURIs(t *testing.T) {
 	for _, test := range genTestTable() {
 		l := lex(test.InputRawDataURI)
 		var items []item
-		for item := range l.items {
+		for {
+			item, ok := l.nextItem()
+			if !ok {
+				break
+			}
 			items = append(items, item)
 		}
 		if !expectItems(test.ExpectedItems, items) {
@@ -270,6 +263,23 @@ func TestLexDataURIs(t *testing.T) {
 	}
 }
 
+func TestLexBytes(t *testing.T) {
+	for _, test := range genTestTable() {
+		l := lex([]byte(test.InputRawDataURI))
+		var items []item
+		for {
+			item, ok := l.nextItem()
+			if !ok {
+				break
+			}
+			items = append(items, lexItem[string]{item.t, string(item.val)})
+		}
+		if !expectItems(test.ExpectedItems, items) {
+			t.Errorf("Expected %v, got %v", test.ExpectedItems, items)
+		}
+	}
+}
+
 func testDataURIs(t *testing.T, factory func(string) (*DataURI, error)) {
 	for _, test := range genTestTable() {
 		var expectedItemError string
@@ -313,6 +323,37 @@ func TestDataURIsWithDecodeString(t *testing.T) {
 	})
 }
 
+func TestDataURIsWithDecodeBytes(t *testing.T) {
+	testDataURIs(t, func(s string) (*DataURI, error) {
+		return DecodeBytes([]byte(s))
+	})
+}
+
+func TestDecodeBytesNoAliasing(t *testing.T) {
+	for _, s := range []string{
+		`data:text/plain;name=a.txt,heya`,
+		`data:text/plain;name="a.txt";base64,aGV5YQ==`,
+	} {
+		b := []byte(s)
+		du, err := DecodeBytes(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range b {
+			b[i] = 'x'
+		}
+		if got := du.ContentType(); got != "text/plain" {
+			t.Errorf("Expected %s, got %s", "text/plain", got)
+		}
+		if got := du.Params["name"]; got != "a.txt" {
+			t.Errorf("Expected %s, got %s", "a.txt", got)
+		}
+		if got := string(du.Data); got != "heya" {
+			t.Errorf("Expected %s, got %s", "heya", got)
+		}
+	}
+}
+
 func TestDataURIsWithUnmarshalText(t *testing.T) {
 	testDataURIs(t, func(s string) (*DataURI, error) {
 		d := &DataURI{}
@@ -513,44 +554,79 @@ func BenchmarkLex(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, test := range genTestTable() {
 			l := lex(test.InputRawDataURI)
-			for range l.items {
+			for _, ok := l.nextItem(); ok; _, ok = l.nextItem() {
 			}
 		}
 	}
 }
 
-const rep = `^data:(?P<mediatype>\w+/[\w\+\-\.]+)?(?P<parameter>(?:;[\w\-]+="?[\w\-\\<>@,";:%]*"?)+)?(?P<base64>;base64)?,(?P<data>.*)$`
+func TestAppendEncode(t *testing.T) {
+	buf := []byte("url(")
+	buf = AppendEncode(buf, []byte("heya"), "text/plain", "charset", "utf-8")
+	buf = append(buf, ')')
+	expected := "url(data:text/plain;charset=utf-8;base64,aGV5YQ==)"
+	if string(buf) != expected {
+		t.Errorf("Expected %s, got %s", expected, buf)
+	}
+}
 
-func TestRegexp(t *testing.T) {
-	re, err := regexp.Compile(rep)
+func TestAppendText(t *testing.T) {
+	ascii := New([]byte("A brief note"), "text/plain")
+	ascii.Encoding = EncodingASCII
+	roundTrip, err := DecodeString("data:text/plain;name=\"a.txt\",heya", WithRoundTrip())
 	if err != nil {
 		t.Fatal(err)
 	}
+	tests := []struct {
+		DataURI        *DataURI
+		ExpectedString string
+	}{
+		{New([]byte("heya"), "text/plain"), "data:text/plain;base64,aGV5YQ=="},
+		{ascii, "data:text/plain,A%20brief%20note"},
+		{roundTrip, "data:text/plain;name=\"a.txt\",heya"},
+		{&DataURI{}, ""},
+	}
+	for _, test := range tests {
+		buf, err := test.DataURI.AppendText([]byte("<"))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(buf) != "<"+test.ExpectedString {
+			t.Errorf("Expected %s, got %s", "<"+test.ExpectedString, buf)
+		}
+		if s := test.DataURI.String(); s != test.ExpectedString {
+			t.Errorf("Expected %s, got %s", test.ExpectedString, s)
+		}
+	}
+
+	invalid := New(nil, "text/plain")
+	invalid.Encoding = "base32"
+	if _, err := invalid.AppendText(nil); err == nil {
+		t.Errorf("Expected an error, got nil")
+	}
+}
+
+func TestConformsTestTable(t *testing.T) {
 	for _, test := range genTestTable() {
-		shouldMatch := true
+		shouldConform := true
 		for _, item := range test.ExpectedItems {
 			if item.t == itemError {
-				shouldMatch = false
+				shouldConform = false
 				break
 			}
 		}
-		// just test it matches, do not parse
-		if re.MatchString(test.InputRawDataURI) && !shouldMatch {
-			t.Error("doesn't match", test.InputRawDataURI)
-		} else if !re.MatchString(test.InputRawDataURI) && shouldMatch {
-			t.Error("match", test.InputRawDataURI)
+		ok, deviations := Conforms(test.InputRawDataURI, ProfileLenient)
+		if ok != shouldConform {
+			t.Errorf("%s: expected Conforms to be %v, got %v", test.InputRawDataURI, shouldConform, deviations)
 		}
 	}
 }
 
-func BenchmarkRegexp(b *testing.B) {
-	re, err := regexp.Compile(rep)
-	if err != nil {
-		b.Fatal(err)
-	}
+func BenchmarkConforms(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, test := range genTestTable() {
-			_ = re.FindStringSubmatch(test.InputRawDataURI)
+			_, _ = Conforms(test.InputRawDataURI, ProfileLenient)
 		}
 	}
 }
@@ -592,3 +668,405 @@ func ExampleDecode() {
 	fmt.Printf("%s: %s", dataURI.Params["name"], dataURI.ContentType())
 	// Output: golang favicon: image/vnd.microsoft.icon
 }
+
+func TestMissingComma(t *testing.T) {
+	tests := []struct {
+		Input              string
+		ExpectMissingComma bool
+	}{
+		{`data:`, true},
+		{`data:text`, true},
+		{`data:text/plain`, true},
+		{`data:text/plain;`, true},
+		{`data:text/plain;charset`, true},
+		{`data:text/plain;charset=`, true},
+		{`data:text/plain;charset=utf-8`, true},
+		{`data:text/plain;name="foo`, true},
+		{`data:text/plain;base64`, true},
+		{`data:xxx,`, false},
+		{`text/plain,`, false},
+	}
+	for _, test := range tests {
+		_, err := DecodeString(test.Input)
+		if err == nil {
+			t.Errorf("%s: expected error", test.Input)
+			continue
+		}
+		if errors.Is(err, ErrMissingComma) != test.ExpectMissingComma {
+			t.Errorf("%s: unexpected error %v", test.Input, err)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		Input          string
+		ExpectedOffset int
+		ExpectedGot    byte
+		ExpectedErr    error
+	}{
+		{"http://example.com", 0, 'h', ErrNotDataURL},
+		{"data:text/pl@in,heya", 12, '@', ErrInvalidMediaType},
+		{"data:foo/bar,heya", 5, 'f', ErrInvalidMediaType},
+		{"data:text/plain;char set=utf-8,heya", 20, ' ', ErrInvalidParam},
+		{"data:text/plain;base64;charset=utf-8,heya", 22, ';', ErrInvalidParam},
+		{"data:text/plain;name=a%2,heya", 22, '%', ErrInvalidParam},
+		{"data:text/plain,he%zya", 18, '%', ErrInvalidData},
+		{"data:;base64,aGV5Y!==", 18, '!', ErrInvalidData},
+		{"data:text/plain", 15, 0, ErrMissingComma},
+	}
+	for _, test := range tests {
+		_, err := DecodeString(test.Input)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%s: expected a *ParseError, got %v", test.Input, err)
+			continue
+		}
+		if parseErr.Offset != test.ExpectedOffset {
+			t.Errorf("%s: expected offset %d, got %d", test.Input, test.ExpectedOffset, parseErr.Offset)
+		}
+		if parseErr.Got != test.ExpectedGot {
+			t.Errorf("%s: expected %q, got %q", test.Input, test.ExpectedGot, parseErr.Got)
+		}
+		if !errors.Is(err, test.ExpectedErr) {
+			t.Errorf("%s: expected %v, got %v", test.Input, test.ExpectedErr, err)
+		}
+	}
+
+	_, err := DecodeString("data:,he%zya")
+	var escErr *EscapeError
+	if !errors.As(err, &escErr) {
+		t.Errorf("Expected an *EscapeError, got %v", err)
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	tests := []struct {
+		Input            string
+		ExpectedType     string
+		ExpectedEncoding string
+	}{
+		{`data:IMAGE/PNG;BASE64,aGV5YQ==`, "image/png", EncodingBase64},
+		{`data:Text/Plain;Base64,aGV5YQ==`, "text/plain", EncodingBase64},
+		{`data:X-Custom/Thing;charset=utf-8;bAsE64,aGV5YQ==`, "x-custom/thing", EncodingBase64},
+		{`data:MultiPart/Mixed,heya`, "multipart/mixed", EncodingASCII},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.Input)
+		if err != nil {
+			t.Errorf("%s: %v", test.Input, err)
+			continue
+		}
+		if got := du.ContentType(); got != test.ExpectedType {
+			t.Errorf("%s: expected %s, got %s", test.Input, test.ExpectedType, got)
+		}
+		if du.Encoding != test.ExpectedEncoding {
+			t.Errorf("%s: expected %s, got %s", test.Input, test.ExpectedEncoding, du.Encoding)
+		}
+		if string(du.Data) != "heya" {
+			t.Errorf("%s: expected %s, got %s", test.Input, "heya", du.Data)
+		}
+	}
+
+	if _, err := DecodeString(`data:;BASE64;charset=utf-8,aGV5YQ==`); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("Expected %v, got %v", ErrInvalidParam, err)
+	}
+}
+
+func TestHasPayload(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected bool
+	}{
+		{`data:,`, false},
+		{`data:;base64,`, false},
+		{`data:,a`, true},
+		{`data:;base64,aGV5YQ==`, true},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.Input)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if du.HasPayload() != test.Expected {
+			t.Errorf("%s: expected HasPayload() to be %v", test.Input, test.Expected)
+		}
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var du DataURI
+	if !du.IsZero() {
+		t.Error("Expected the zero DataURI to be zero")
+	}
+	if s := du.String(); s != "" {
+		t.Errorf("Expected empty string, got %q", s)
+	}
+	txt, err := du.MarshalText()
+	if err != nil || len(txt) != 0 {
+		t.Errorf("Expected empty text, got %q, %v", txt, err)
+	}
+
+	du = *New([]byte("heya"), "text/plain")
+	if du.IsZero() {
+		t.Error("Expected a non-zero DataURI")
+	}
+	if err := du.UnmarshalText(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !du.IsZero() {
+		t.Errorf("Expected zero DataURI, got %v", du)
+	}
+
+	decoded, err := DecodeString(`data:,`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.IsZero() {
+		t.Errorf("Expected %s not to be zero", decoded)
+	}
+}
+
+func TestStringWith(t *testing.T) {
+	untyped := &DataURI{Encoding: EncodingASCII, Data: []byte("heya")}
+	tests := []struct {
+		DataURI  *DataURI
+		Opts     []EncodeOption
+		Expected string
+	}{
+		{untyped, nil, `data:,heya`},
+		{untyped, []EncodeOption{AlwaysEmitMediaType()}, `data:text/plain,heya`},
+		{untyped, []EncodeOption{AlwaysEmitCharset()}, `data:;charset=US-ASCII,heya`},
+		{untyped, []EncodeOption{AlwaysEmitMediaType(), AlwaysEmitCharset()}, `data:text/plain;charset=US-ASCII,heya`},
+		{New([]byte("heya"), "text/plain", "charset", "utf-8"), []EncodeOption{AlwaysEmitCharset()}, `data:text/plain;charset=utf-8;base64,aGV5YQ==`},
+		{New([]byte("heya"), "text/csv"), []EncodeOption{AlwaysEmitCharset()}, `data:text/csv;charset=US-ASCII;base64,aGV5YQ==`},
+		{New([]byte("heya"), "image/png"), []EncodeOption{AlwaysEmitMediaType(), AlwaysEmitCharset()}, `data:image/png;base64,aGV5YQ==`},
+	}
+	for _, test := range tests {
+		if s := test.DataURI.StringWith(test.Opts...); s != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, s)
+		}
+	}
+	if untyped.Params != nil {
+		t.Errorf("Expected params to be left unmodified, got %v", untyped.Params)
+	}
+}
+
+func largePayload() []byte {
+	data := make([]byte, 4<<20)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func BenchmarkDecodeStringSmall(b *testing.B) {
+	s := `data:text/plain;charset=utf-8;base64,aGV5YQ==`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeString(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBytesLarge(b *testing.B) {
+	data := largePayload()
+	s := []byte(New(data, "application/octet-stream").String())
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeBytes(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeStringLarge(b *testing.B) {
+	data := largePayload()
+	s := New(data, "application/octet-stream").String()
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeString(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeStringLargeASCII(b *testing.B) {
+	data := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 100000)
+	du := New(data, "text/plain")
+	du.Encoding = EncodingASCII
+	s := du.String()
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeString(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteToLarge(b *testing.B) {
+	du := New(largePayload(), "application/octet-stream")
+	b.SetBytes(int64(len(du.Data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := du.WriteTo(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestURLSafeBase64(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xbf, 0xfe}
+	tests := []string{
+		`data:;base64,` + base64.URLEncoding.EncodeToString(data),
+		`data:;base64,` + base64.StdEncoding.EncodeToString(data),
+	}
+	for _, s := range tests {
+		du, err := DecodeString(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if !bytes.Equal(du.Data, data) {
+			t.Errorf("Expected %v, got %v", data, du.Data)
+		}
+	}
+	if _, err := DecodeString(`data:;base64,+_-_`); err == nil {
+		t.Error("Expected mixed alphabets to fail")
+	}
+	if _, err := DecodeString(tests[0], WithStrictRFC2397()); !errors.Is(err, ErrNotConformant) {
+		t.Errorf("Expected %v, got %v", ErrNotConformant, err)
+	}
+}
+
+func TestUnpaddedBase64(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected string
+	}{
+		{`data:;base64,aGV5YQ`, "heya"},
+		{`data:;base64,aGU`, "he"},
+		{"data:;base64,aGV5\nYQ\n", "heya"},
+		{`data:;base64,-_-_-w`, "\xfb\xff\xbf\xfb"},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.Input)
+		if err != nil {
+			t.Errorf("%q: %v", test.Input, err)
+			continue
+		}
+		if string(du.Data) != test.Expected {
+			t.Errorf("Expected %q, got %q", test.Expected, du.Data)
+		}
+		if _, err := DecodeString(test.Input, WithMaxDataSize(int64(len(test.Expected)))); err != nil {
+			t.Errorf("%q: expected the size to be computed exactly, got %v", test.Input, err)
+		}
+	}
+	for _, input := range []string{`data:;base64,aGV5Y`, `data:;base64,aGV5YQ=`} {
+		if _, err := DecodeString(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestNewASCII(t *testing.T) {
+	tests := []struct {
+		Data       string
+		MediaType  string
+		ParamPairs []string
+		Expected   string
+	}{
+		{"A brief note", "text/plain", nil, "data:text/plain,A%20brief%20note"},
+		{"A brief note", "text/plain", []string{"charset", "US-ASCII"}, "data:text/plain;charset=US-ASCII,A%20brief%20note"},
+		{"café", "text/plain", []string{"charset", "utf-8"}, "data:text/plain;charset=utf-8,caf%C3%A9"},
+		{"", "text/html", nil, "data:text/html,"},
+	}
+	for _, test := range tests {
+		du := NewASCII([]byte(test.Data), test.MediaType, test.ParamPairs...)
+		if got := du.String(); got != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, got)
+		}
+		decoded, err := DecodeString(du.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(decoded.Data) != test.Data {
+			t.Errorf("Expected %s, got %s", test.Data, decoded.Data)
+		}
+	}
+}
+
+func TestNewChecked(t *testing.T) {
+	tests := []struct {
+		MediaType  string
+		ParamPairs []string
+		Expected   string
+	}{
+		{"text/plain", nil, "data:text/plain;base64,aGV5YQ=="},
+		{"Text/Plain", []string{"charset", "utf-8"}, "data:Text/Plain;charset=utf-8;base64,aGV5YQ=="},
+		{"text/plain; charset=utf-8", nil, "data:text/plain;charset=utf-8;base64,aGV5YQ=="},
+		{"text/plain;charset=utf-8;name=a", []string{"charset", "latin1"}, "data:text/plain;charset=latin1;name=a;base64,aGV5YQ=="},
+	}
+	for _, test := range tests {
+		du, err := NewChecked([]byte("heya"), test.MediaType, test.ParamPairs...)
+		if err != nil {
+			t.Errorf("%s: %v", test.MediaType, err)
+			continue
+		}
+		if got := du.String(); got != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, got)
+		}
+	}
+
+	errTests := []struct {
+		MediaType  string
+		ParamPairs []string
+	}{
+		{"text", nil},
+		{"text/", nil},
+		{"/plain", nil},
+		{"text/plain/html", nil},
+		{"text/pl ain", nil},
+		{"text/plain;charset", nil},
+		{"text/plain", []string{"charset"}},
+		{"text/plain", []string{"char set", "utf-8"}},
+	}
+	for _, test := range errTests {
+		if _, err := NewChecked([]byte("heya"), test.MediaType, test.ParamPairs...); err == nil {
+			t.Errorf("%s %v: expected error", test.MediaType, test.ParamPairs)
+		}
+	}
+}
+
+func FuzzDecodeString(f *testing.F) {
+	for _, test := range genTestTable() {
+		f.Add(test.InputRawDataURI)
+	}
+	for _, s := range []string{"", "data:", "data:text", "data:text/", "data:;charset=\"a", "data:;charset=\"a\\", "data:;a=%", "data:;base64,%"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		_, err := DecodeString(s)
+		if _, bytesErr := DecodeBytes([]byte(s)); (err == nil) != (bytesErr == nil) {
+			t.Fatalf("%q: expected error %v, got %v", s, err, bytesErr)
+		}
+		if validateErr := Validate(s); (err == nil) != (validateErr == nil) || err != nil && err.Error() != validateErr.Error() {
+			t.Fatalf("%q: expected error %v, got %v", s, err, validateErr)
+		}
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("%q: expected a *ParseError, got %T", s, err)
+			}
+		}
+	})
+}
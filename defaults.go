@@ -0,0 +1,78 @@
+package datauri
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	defaultsMu sync.RWMutex
+	// defaultParams maps media type patterns to the parameters
+	// written by default for the matching media types.
+	defaultParams = map[string]map[string]string{}
+)
+
+// RegisterDefaultParams registers the parameters, given as attribute/value
+// pairs, written by default when serializing a DataURI whose media type
+// matches pattern, following the same rules as RegisterDecoder.
+// It replaces the parameters previously registered for pattern, and
+// unregisters them when paramPairs is empty.
+// paramPairs must have an even number of elements or it will panic.
+//
+// A parameter is only added when the DataURI has no parameter of the
+// same attribute. An empty value removes the attribute instead,
+// so that e.g.
+//
+//	RegisterDefaultParams("application/json", "charset", "utf-8")
+//	RegisterDefaultParams("image/*", "charset", "")
+//
+// always writes a charset for JSON and never for images.
+//
+// The default parameters are not applied to the media type of Data URIs
+// written as decoded with WithRoundTrip, nor when it is omitted.
+func RegisterDefaultParams(pattern string, paramPairs ...string) {
+	if len(paramPairs)%2 != 0 {
+		panic("datauri: requires an even number of param pairs")
+	}
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	pattern = strings.ToLower(pattern)
+	if len(paramPairs) == 0 {
+		delete(defaultParams, pattern)
+		return
+	}
+	params := make(map[string]string, len(paramPairs)/2)
+	for i := 0; i < len(paramPairs); i += 2 {
+		params[paramPairs[i]] = paramPairs[i+1]
+	}
+	defaultParams[pattern] = params
+}
+
+// withDefaultParams returns mt with the default parameters
+// registered for its media type applied.
+func withDefaultParams(mt MediaType) MediaType {
+	if mt.Type == "" && mt.Subtype == "" {
+		return mt
+	}
+	defaults := lookupPattern(&defaultsMu, defaultParams, mt.Type, mt.Subtype)
+	if len(defaults) == 0 {
+		return mt
+	}
+	params := make(map[string]string, len(mt.Params)+len(defaults))
+	for k, v := range mt.Params {
+		params[k] = v
+	}
+	for attr, v := range defaults {
+		if v == "" {
+			for k := range params {
+				if strings.EqualFold(k, attr) {
+					delete(params, k)
+				}
+			}
+		} else if _, ok := lookupParam(&mt, attr); !ok {
+			params[attr] = v
+		}
+	}
+	mt.Params = params
+	return mt
+}
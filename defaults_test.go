@@ -0,0 +1,55 @@
+package datauri
+
+import "testing"
+
+func TestRegisterDefaultParams(t *testing.T) {
+	RegisterDefaultParams("application/json", "charset", "utf-8")
+	RegisterDefaultParams("image/*", "charset", "")
+	defer RegisterDefaultParams("application/json")
+	defer RegisterDefaultParams("image/*")
+
+	tests := []struct {
+		du       *DataURI
+		expected string
+	}{
+		{New([]byte("{}"), "application/json"), "data:application/json;charset=utf-8;base64,e30="},
+		{New([]byte("{}"), "Application/JSON"), "data:Application/JSON;charset=utf-8;base64,e30="},
+		{New([]byte("{}"), "application/json", "CHARSET", "utf-16"), "data:application/json;CHARSET=utf-16;base64,e30="},
+		{New([]byte("GIF8"), "image/gif", "charset", "utf-8", "Charset", "utf-8"), "data:image/gif;base64,R0lGOA=="},
+		{New([]byte("GIF8"), "image/gif", "name", "a"), "data:image/gif;name=a;base64,R0lGOA=="},
+		{New([]byte("hey"), "text/plain"), "data:text/plain;base64,aGV5"},
+	}
+	for _, test := range tests {
+		if got := test.du.String(); got != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, got)
+		}
+	}
+
+	du, err := DecodeString("data:application/json,{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := du.header(); got != "data:application/json;charset=utf-8," {
+		t.Errorf("Expected %s, got %s", "data:application/json;charset=utf-8,", got)
+	}
+	if _, ok := du.Params["charset"]; ok {
+		t.Errorf("Expected the decoded params to be left unmodified, got %v", du.Params)
+	}
+
+	du, err = DecodeString("data:application/json,{}", WithRoundTrip())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := du.String(); got != "data:application/json,{}" {
+		t.Errorf("Expected %s, got %s", "data:application/json,{}", got)
+	}
+}
+
+func TestRegisterDefaultParamsUnregister(t *testing.T) {
+	RegisterDefaultParams("+xml", "charset", "utf-8")
+	RegisterDefaultParams("+xml")
+	du := New([]byte("<a/>"), "image/svg+xml")
+	if got := du.String(); got != "data:image/svg+xml;base64,PGEvPg==" {
+		t.Errorf("Expected %s, got %s", "data:image/svg+xml;base64,PGEvPg==", got)
+	}
+}
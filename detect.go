@@ -0,0 +1,98 @@
+package datauri
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Detector detects the media type of a payload.
+type Detector interface {
+	// Detect returns the media type of data, which may hold parameters,
+	// e.g. "text/plain;charset=utf-8", or an empty string if unknown.
+	// data may only be the first bytes of the payload.
+	Detect(data []byte) string
+}
+
+// DetectorFunc is a function used as a Detector.
+type DetectorFunc func(data []byte) string
+
+// Detect implements the Detector interface, calling f.
+func (f DetectorFunc) Detect(data []byte) string {
+	return f(data)
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   []Detector
+)
+
+// RegisterDetector registers d to detect the media types of payloads,
+// e.g. with a library of magic numbers, for the formats which
+// http.DetectContentType does not know, like AVIF, HEIC or documents.
+// The detectors are tried in the order they are registered, before
+// http.DetectContentType, the default, and the first media type found
+// is used. The invalid media types they return are ignored.
+func RegisterDetector(d Detector) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectors = append(detectors, d)
+}
+
+// DetectWithHints returns the media type of data.
+//
+// The media type is detected with the registered Detectors, then with
+// http.DetectContentType, see RegisterDetector. When this
+// only yields a generic result (application/octet-stream, text/plain
+// or application/zip, the container of many document formats),
+// the extension of filename is used to find a more specific media type
+// with mime.TypeByExtension, if any.
+func DetectWithHints(data []byte, filename string) string {
+	mt := detectContentType(data)
+	if filename == "" || !isGenericContentType(mt) {
+		return mt
+	}
+	if byExt := mime.TypeByExtension(path.Ext(filename)); byExt != "" {
+		return cleanContentType(byExt)
+	}
+	return mt
+}
+
+// EncodeBytesWithHints is like EncodeBytes, but uses DetectWithHints
+// to detect the media type of data.
+func EncodeBytesWithHints(data []byte, filename string) string {
+	return newDetected(data, filename).String()
+}
+
+func detectContentType(data []byte) string {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	for _, d := range detectors {
+		mt := cleanContentType(d.Detect(data))
+		if mt == "" {
+			continue
+		}
+		if _, err := NewChecked(nil, mt); err == nil {
+			return mt
+		}
+	}
+	return cleanContentType(http.DetectContentType(data))
+}
+
+// cleanContentType removes the spurious spaces between ; and
+// a parameter that http.DetectContentType and mime.TypeByExtension
+// may add. The canonical way is to not have them.
+func cleanContentType(mt string) string {
+	return strings.ReplaceAll(mt, "; ", ";")
+}
+
+func isGenericContentType(mt string) bool {
+	base, _, _ := strings.Cut(mt, ";")
+	switch base {
+	case "application/octet-stream", "text/plain", "application/zip":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package datauri
+
+import (
+	"testing"
+)
+
+func TestDetectWithHints(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+	tests := []struct {
+		Data     []byte
+		Filename string
+		Expected string
+	}{
+		{[]byte(`{"msg": "heya"}`), "", "text/plain;charset=utf-8"},
+		{[]byte(`{"msg": "heya"}`), "msg.json", "application/json"},
+		{[]byte(`{"msg": "heya"}`), "msg", "text/plain;charset=utf-8"},
+		{[]byte(`{"msg": "heya"}`), "msg.unknown-extension", "text/plain;charset=utf-8"},
+		{[]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), "logo.svg", "image/svg+xml"},
+		{[]byte{0x00, 0x01, 0x02}, "logo.png", "image/png"},
+		{png, "logo.json", "image/png"},
+		{png, "", "image/png"},
+	}
+	for _, test := range tests {
+		if got := DetectWithHints(test.Data, test.Filename); got != test.Expected {
+			t.Errorf("%q, %q: expected %s, got %s", test.Data, test.Filename, test.Expected, got)
+		}
+	}
+}
+
+func TestEncodeBytesWithHints(t *testing.T) {
+	expected := "data:application/json;base64,eyJtc2ciOiAiaGV5YSJ9"
+	if got := EncodeBytesWithHints([]byte(`{"msg": "heya"}`), "msg.json"); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestRegisterDetector(t *testing.T) {
+	avif := []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00")
+	RegisterDetector(DetectorFunc(func(data []byte) string {
+		if len(data) >= 12 && string(data[4:12]) == "ftypavif" {
+			return "image/avif"
+		}
+		return ""
+	}))
+	RegisterDetector(DetectorFunc(func(data []byte) string {
+		// invalid media types are ignored
+		return "invalid"
+	}))
+
+	tests := []struct {
+		Data     []byte
+		Expected string
+	}{
+		{avif, "image/avif"},
+		{[]byte("\x89PNG\x0D\x0A\x1A\x0A"), "image/png"},
+		{[]byte("heya"), "text/plain;charset=utf-8"},
+	}
+	for _, test := range tests {
+		if got := DetectWithHints(test.Data, ""); got != test.Expected {
+			t.Errorf("%q: expected %s, got %s", test.Data, test.Expected, got)
+		}
+	}
+	if expected, got := "data:image/avif;base64,AAAAHGZ0eXBhdmlmAAAAAA==", EncodeBytes(avif); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,111 @@
+package datauri
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// Encoder encodes what is written to it as the base64 payload of
+// a Data URI written to an underlying writer, see NewEncoder.
+type Encoder struct {
+	w      io.Writer
+	header string
+	enc    io.WriteCloser // nil until the header is written
+}
+
+// NewEncoder returns an Encoder writing to w a Data URI of a MediaType
+// parsed from mediatype and paramPairs, as for New.
+//
+// The header is written on the first call to Write, Flush or Close.
+// Close must be called to flush the end of the payload; it does not
+// close w.
+func NewEncoder(w io.Writer, mediatype string, paramPairs ...string) *Encoder {
+	du := New(nil, mediatype, paramPairs...)
+	return &Encoder{
+		w:      w,
+		header: du.header(),
+	}
+}
+
+func (e *Encoder) start() error {
+	if e.enc != nil {
+		return nil
+	}
+	if _, err := io.WriteString(e.w, e.header); err != nil {
+		return err
+	}
+	e.enc = base64.NewEncoder(base64.StdEncoding, e.w)
+	return nil
+}
+
+// Write implements the io.Writer interface.
+// Whole 3-byte groups are encoded to the underlying writer right away,
+// the remaining bytes are held until the next call to Write or Close.
+func (e *Encoder) Write(p []byte) (int, error) {
+	if err := e.start(); err != nil {
+		return 0, err
+	}
+	return e.enc.Write(p)
+}
+
+// Flush writes the header if not done yet, then flushes the underlying
+// writer if it is buffered, like a *bufio.Writer, or an http.Flusher,
+// so that a client receives the payload written so far, e.g. for the
+// progressive rendering of a large inline attachment.
+//
+// Unlike Close, Flush does not pad the payload: the up to 2 bytes not
+// making a whole 3-byte group are held until more are written.
+func (e *Encoder) Flush() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	return flush(e.w)
+}
+
+// Close writes the end of the payload, with its padding,
+// and flushes the underlying writer like Flush.
+func (e *Encoder) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if err := e.enc.Close(); err != nil {
+		return err
+	}
+	return flush(e.w)
+}
+
+// flush flushes w if it is buffered.
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		// http.Flusher
+		f.Flush()
+	}
+	return nil
+}
+
+// EncodeReader encodes what is read from r into a Data URI written to w,
+// using base 64 encoding, like EncodeBytes, without holding the payload
+// in memory. The media type is detected as by EncodeBytes,
+// from the first 512 bytes of r, then the rest of r is streamed.
+func EncodeReader(r io.Reader, w io.Writer) error {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	head = head[:n]
+	e := &Encoder{
+		w:      w,
+		header: newDetected(head, "").header(),
+	}
+	if _, err := e.Write(head); err != nil {
+		return err
+	}
+	if _, err := io.Copy(e, r); err != nil {
+		return err
+	}
+	return e.Close()
+}
@@ -0,0 +1,140 @@
+package datauri
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, "text/plain", "charset", "utf-8")
+	if buf.Len() != 0 {
+		t.Errorf("Expected the header to be written lazily, got %s", buf.String())
+	}
+	for _, s := range []string{"he", "y", "a"} {
+		if _, err := io.WriteString(enc, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected := New([]byte("heya"), "text/plain", "charset", "utf-8").String()
+	if buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+}
+
+func TestNewEncoderLarge(t *testing.T) {
+	data := largePayload()
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, "application/octet-stream")
+	if _, err := io.Copy(enc, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	du, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(du.Data, data) {
+		t.Error("Expected the payload to round trip")
+	}
+}
+
+func TestNewEncoderEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, "image/png").Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "data:image/png;base64,"; buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+}
+
+func TestEncoderFlush(t *testing.T) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	enc := NewEncoder(bw, "image/png")
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "data:image/png;base64,"; buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+
+	if _, err := enc.Write([]byte("heyaa")); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// "aa" is held, without padding
+	if expected := "data:image/png;base64,aGV5"; buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+
+	if _, err := enc.Write([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "data:image/png;base64,aGV5YWFh"; buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+}
+
+func TestEncoderHTTPFlusher(t *testing.T) {
+	rec := httptest.NewRecorder()
+	enc := NewEncoder(rec, "text/plain")
+	if _, err := enc.Write([]byte("heya")); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Flushed {
+		t.Error("Expected the response to be flushed")
+	}
+	if expected := "data:text/plain;base64,aGV5"; rec.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, rec.Body.String())
+	}
+}
+
+func TestEncodeReader(t *testing.T) {
+	inputs := [][]byte{
+		nil,
+		[]byte("heya"),
+		[]byte("GIF89a"),
+		largePayload(),
+	}
+	for _, input := range inputs {
+		var buf bytes.Buffer
+		if err := EncodeReader(iotest.HalfReader(bytes.NewReader(input)), &buf); err != nil {
+			t.Fatal(err)
+		}
+		if expected := EncodeBytes(input); len(input) > 0 && buf.String() != expected {
+			t.Errorf("Expected %.50s, got %.50s", expected, buf.String())
+		}
+		du, err := DecodeString(buf.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(du.Data, input) {
+			t.Errorf("Expected %.20q, got %.20q", input, du.Data)
+		}
+	}
+
+	errRead := errors.New("read error")
+	if err := EncodeReader(iotest.ErrReader(errRead), io.Discard); !errors.Is(err, errRead) {
+		t.Errorf("Expected %v, got %v", errRead, err)
+	}
+}
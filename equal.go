@@ -0,0 +1,49 @@
+package datauri
+
+import (
+	"bytes"
+	"crypto/subtle"
+)
+
+// Equal reports whether du and other have the same media type, with
+// the same parameters, the same encoding and the same payload.
+// A nil and an empty Params, or Data, are equal.
+func (du *DataURI) Equal(other *DataURI) bool {
+	if du == nil || other == nil {
+		return du == other
+	}
+	if du.Type != other.Type || du.Subtype != other.Subtype || du.Encoding != other.Encoding ||
+		len(du.Params) != len(other.Params) {
+		return false
+	}
+	for k, v := range du.Params {
+		if ov, ok := other.Params[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return bytes.Equal(du.Data, other.Data)
+}
+
+// EqualContent reports whether du and other hold the same payload with
+// the same media type, whatever their encoding. The media types and the
+// parameter attributes are compared case-insensitively.
+func (du *DataURI) EqualContent(other *DataURI) bool {
+	if du == nil || other == nil {
+		return du == other
+	}
+	return compatibleMediaTypes(&du.MediaType, &other.MediaType) && bytes.Equal(du.Data, other.Data)
+}
+
+// EqualConstantTime reports whether du and other have the same media type
+// and payload, comparing the payloads in constant time with crypto/subtle,
+// so that the comparison of secret payloads doesn't leak timing information.
+//
+// Only the time taken to compare payloads of different lengths,
+// and the comparison of media types, may depend on their content.
+func (du *DataURI) EqualConstantTime(other *DataURI) bool {
+	if du == nil || other == nil {
+		return du == other
+	}
+	sameData := subtle.ConstantTimeCompare(du.Data, other.Data) == 1
+	return compatibleMediaTypes(&du.MediaType, &other.MediaType) && sameData
+}
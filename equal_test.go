@@ -0,0 +1,55 @@
+package datauri
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	du := New([]byte("heya"), "text/plain", "charset", "utf-8")
+	ascii := New([]byte("heya"), "text/plain", "charset", "utf-8")
+	ascii.Encoding = EncodingASCII
+	tests := []struct {
+		A, B                           *DataURI
+		ExpectedEqual, ExpectedContent bool
+	}{
+		{du, New([]byte("heya"), "text/plain", "charset", "utf-8"), true, true},
+		{du, ascii, false, true},
+		{du, New([]byte("heya"), "TEXT/Plain", "Charset", "utf-8"), false, true},
+		{du, New([]byte("heya"), "text/plain", "charset", "UTF-8"), false, false},
+		{du, New([]byte("heya"), "text/plain"), false, false},
+		{du, New([]byte("heyA"), "text/plain", "charset", "utf-8"), false, false},
+		{du, New([]byte("heya"), "text/html", "charset", "utf-8"), false, false},
+		{New(nil, "text/plain"), &DataURI{MediaType: MediaType{Type: "text", Subtype: "plain"}, Encoding: EncodingBase64, Data: []byte{}}, true, true},
+		{du, nil, false, false},
+		{nil, nil, true, true},
+	}
+	for _, test := range tests {
+		if got := test.A.Equal(test.B); got != test.ExpectedEqual {
+			t.Errorf("%v, %v: expected Equal %v, got %v", test.A, test.B, test.ExpectedEqual, got)
+		}
+		if got := test.A.EqualContent(test.B); got != test.ExpectedContent {
+			t.Errorf("%v, %v: expected EqualContent %v, got %v", test.A, test.B, test.ExpectedContent, got)
+		}
+	}
+}
+
+func TestEqualConstantTime(t *testing.T) {
+	key := New([]byte("s3cr3t"), "application/octet-stream", "name", "key")
+	tests := []struct {
+		A, B     *DataURI
+		Expected bool
+	}{
+		{key, New([]byte("s3cr3t"), "application/octet-stream", "name", "key"), true},
+		{key, New([]byte("s3cr3T"), "application/octet-stream", "name", "key"), false},
+		{key, New([]byte("s3cr3"), "application/octet-stream", "name", "key"), false},
+		{key, New([]byte("s3cr3t"), "application/octet-stream"), false},
+		{key, New([]byte("s3cr3t"), "text/plain", "name", "key"), false},
+		{key, nil, false},
+		{nil, nil, true},
+	}
+	for _, test := range tests {
+		if got := test.A.EqualConstantTime(test.B); got != test.Expected {
+			t.Errorf("%v, %v: expected %v, got %v", test.A, test.B, test.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/invopop/datauri"
+)
+
+// ExtractCSS returns the Data URIs found in the url() values of the
+// stylesheet read from r, in the order of the stylesheet, skipping
+// comments. The Data URIs which fail to decode are returned too, with
+// their error.
+func ExtractCSS(r io.Reader) ([]Found, error) {
+	return extractAll(r, cssURLs)
+}
+
+// RewriteCSS copies the stylesheet read from r to w, with the Data URIs
+// of its url() values replaced by what fn returns for them, e.g. the path
+// of a file holding the payload, or an optimized Data URI. The replacement
+// is quoted as needed. The Data URIs which fail to decode are left as is.
+// The stylesheet is held in memory.
+//
+// RewriteCSS stops at the first error returned by fn, before writing
+// anything to w.
+func RewriteCSS(r io.Reader, w io.Writer, fn func(*datauri.DataURI) (string, error)) error {
+	return rewrite(r, w, cssURLs, quoteURL, fn)
+}
+
+// quoteURL returns s written in a url() value after prev, the byte
+// preceding the replaced URL: s is escaped in a quoted URL, and quoted
+// in an unquoted one when needed.
+func quoteURL(s string, prev byte) string {
+	if prev == '"' || prev == '\'' {
+		return escapeCSSString(s, prev)
+	}
+	if strings.ContainsAny(s, " \t\n\r\f\"'()\\") {
+		return `"` + escapeCSSString(s, '"') + `"`
+	}
+	return s
+}
+
+func escapeCSSString(s string, quote byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == quote || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n' || c == '\r' || c == '\f':
+			fmt.Fprintf(&b, "\\%x ", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// cssURLs returns the spans in css of the Data URIs of its url()
+// values, quoted or not, without their quotes. Comments and strings
+// are skipped. css is scanned once, forward.
+func cssURLs(css string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(css); {
+		switch c := css[i]; {
+		case c == '/' && strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				return spans
+			}
+			i += 2 + end + 2
+		case c == '"' || c == '\'':
+			i = skipCSSString(css, i+1, c)
+		case (c == 'u' || c == 'U') && hasPrefixFold(css[i:], "url("):
+			start := i + len("url(")
+			for start < len(css) && isCSSSpace(css[start]) {
+				start++
+			}
+			var end int
+			if start < len(css) && (css[start] == '"' || css[start] == '\'') {
+				quote := css[start]
+				start++
+				i = skipCSSString(css, start, quote)
+				end = i
+				if end > start && css[end-1] == quote {
+					end--
+				}
+			} else {
+				end = start
+				for end < len(css) && css[end] != ')' && !isCSSSpace(css[end]) {
+					end++
+				}
+				i = end
+			}
+			if isDataURI(css[start:end]) {
+				spans = append(spans, [2]int{start, end})
+			}
+		default:
+			i++
+		}
+	}
+	return spans
+}
+
+// skipCSSString returns the offset following the end of the string
+// of css starting at i, after its opening quote: after its closing
+// quote, or the end of css.
+func skipCSSString(css string, i int, quote byte) int {
+	for i < len(css) {
+		switch css[i] {
+		case quote:
+			return i + 1
+		case '\\':
+			i++
+		}
+		i++
+	}
+	return len(css)
+}
+
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// hasPrefixFold reports whether s begins with prefix,
+// ASCII case-insensitively.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
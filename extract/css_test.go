@@ -0,0 +1,133 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/invopop/datauri"
+)
+
+const testCSS = `@font-face { src: url(data:font/woff2;base64,d09GMg==) format("woff2"); }
+/* .old { background: url(data:image/png;base64,aGV5YQ==) } */
+.logo { background: URL( "data:image/svg+xml,<svg xmlns='http://www.w3.org/2000/svg'/>" ) }
+.icon { background: url('data:image/png;base64,aGV5YQ=='), url(/img/bg.png); }
+.bad { background: url(data:image/png;base64,%%%) }`
+
+func TestExtractCSS(t *testing.T) {
+	found, err := ExtractCSS(strings.NewReader(testCSS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		Raw string
+		OK  bool
+	}{
+		{"data:font/woff2;base64,d09GMg==", true},
+		{"data:image/svg+xml,<svg xmlns='http://www.w3.org/2000/svg'/>", true},
+		{"data:image/png;base64,aGV5YQ==", true},
+		{"data:image/png;base64,%%%", false},
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("Expected %d Data URIs, got %d: %v", len(expected), len(found), found)
+	}
+	for i, f := range found {
+		if f.Raw != expected[i].Raw || (f.Err == nil) != expected[i].OK {
+			t.Errorf("%d: expected %v, got %v", i, expected[i], f)
+		}
+		if !strings.HasPrefix(testCSS[f.Offset:], f.Raw) {
+			t.Errorf("%d: expected %s at offset %d, got %.20s", i, f.Raw, f.Offset, testCSS[f.Offset:])
+		}
+	}
+}
+
+func TestRewriteCSS(t *testing.T) {
+	var n int
+	var b strings.Builder
+	err := RewriteCSS(strings.NewReader(testCSS), &b, func(du *datauri.DataURI) (string, error) {
+		n++
+		ext, _ := du.SuggestedExtension()
+		return fmt.Sprintf("/assets/%d%s", n, ext), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `@font-face { src: url(/assets/1.woff2) format("woff2"); }
+/* .old { background: url(data:image/png;base64,aGV5YQ==) } */
+.logo { background: URL( "/assets/2.svg" ) }
+.icon { background: url('/assets/3.png'), url(/img/bg.png); }
+.bad { background: url(data:image/png;base64,%%%) }`
+	if b.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, b.String())
+	}
+
+	errFn := errors.New("fn failed")
+	b.Reset()
+	err = RewriteCSS(strings.NewReader(testCSS), &b, func(*datauri.DataURI) (string, error) {
+		return "", errFn
+	})
+	if !errors.Is(err, errFn) || b.Len() != 0 {
+		t.Errorf("Expected %v and nothing written, got %v and %q", errFn, err, b.String())
+	}
+}
+
+func TestQuoteURL(t *testing.T) {
+	tests := []struct {
+		S        string
+		Prev     byte
+		Expected string
+	}{
+		{"/a.png", '(', "/a.png"},
+		{"/a b.png", '(', `"/a b.png"`},
+		{`/a"b).png`, '(', `"/a\"b).png"`},
+		{`/a'b.png`, '\'', `/a\'b.png`},
+		{"/a\nb.png", '"', `/a\a b.png`},
+	}
+	for _, test := range tests {
+		if got := quoteURL(test.S, test.Prev); got != test.Expected {
+			t.Errorf("%q: expected %s, got %s", test.S, test.Expected, got)
+		}
+	}
+	if got := cssURLs("url(data:,a"); !reflect.DeepEqual(got, [][2]int{{4, 11}}) {
+		t.Errorf("Expected %v, got %v", [][2]int{{4, 11}}, got)
+	}
+}
+
+func TestCSSURLs(t *testing.T) {
+	tests := []struct {
+		CSS      string
+		Expected []string
+	}{
+		{`.a { content: "url(data:,x)"; background: url(data:,y) }`, []string{"data:,y"}},
+		{`.a { content: "/*"; background: url(data:,z) } /* url(data:,c) */`, []string{"data:,z"}},
+		{`.a { content: 'it\'s url(data:,x)'; b: url("data:,\"q") }`, []string{`data:,\"q`}},
+		{`.a { b: url(data:,y) } /* unclosed url(data:,c)`, []string{"data:,y"}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, span := range cssURLs(test.CSS) {
+			got = append(got, test.CSS[span[0]:span[1]])
+		}
+		if !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("%s: expected %v, got %v", test.CSS, test.Expected, got)
+		}
+	}
+}
+
+func TestCSSURLsLinear(t *testing.T) {
+	for _, css := range []string{
+		strings.Repeat(".a { background: url(/img/a.png) }\n", 20000),
+		strings.Repeat("/* a comment */", 20000),
+	} {
+		start := time.Now()
+		if spans := cssURLs(css); len(spans) != 0 {
+			t.Errorf("Expected no Data URI, got %d", len(spans))
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected the stylesheet to be scanned in linear time, took %v", elapsed)
+		}
+	}
+}
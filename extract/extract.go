@@ -0,0 +1,96 @@
+// Package extract finds the Data URIs embedded in documents, HTML
+// documents, CSS stylesheets and Markdown documents, with their position,
+// e.g. to audit embedded assets, and rewrites them.
+//
+// It is a separate module, so that the datauri module stays free of
+// dependencies.
+package extract
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/invopop/datauri"
+)
+
+// Found is a Data URI found in a document.
+type Found struct {
+	// DataURI is the decoded Data URI, nil if it failed to decode.
+	DataURI *datauri.DataURI
+	// Err is the error decoding the Data URI, if any.
+	Err error
+	// Raw is the Data URI as written in the document, once
+	// the character references of HTML attributes are resolved.
+	Raw string
+	// Path is the path of the HTML element holding the Data URI,
+	// from the root of the document, e.g. "html/body/div/img".
+	// It is empty in stylesheets.
+	Path string
+	// Attr is the HTML attribute holding the Data URI:
+	// src, href, srcset or style. It is empty in stylesheets.
+	Attr string
+	// Offset is the offset of the Data URI in the document. In HTML
+	// attribute values written with character references, it is the
+	// offset of the start tag holding it.
+	Offset int
+}
+
+func newFound(raw string) Found {
+	du, err := datauri.DecodeString(raw)
+	return Found{DataURI: du, Err: err, Raw: raw}
+}
+
+// isDataURI reports whether s starts with the data scheme.
+func isDataURI(s string) bool {
+	return len(s) >= 5 && strings.EqualFold(s[:5], "data:")
+}
+
+// extractAll returns the Data URIs of the document read from r,
+// found at the spans returned by find.
+func extractAll(r io.Reader, find func(string) [][2]int) ([]Found, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc := string(b)
+	var found []Found
+	for _, span := range find(doc) {
+		f := newFound(doc[span[0]:span[1]])
+		f.Offset = span[0]
+		found = append(found, f)
+	}
+	return found, nil
+}
+
+// rewrite copies the document read from r to w, with the Data URIs
+// found at the spans returned by find replaced by what fn returns for
+// them, written by quote after the byte preceding them.
+func rewrite(r io.Reader, w io.Writer, find func(string) [][2]int,
+	quote func(s string, prev byte) string, fn func(*datauri.DataURI) (string, error)) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var (
+		doc  = string(b)
+		out  strings.Builder
+		last int
+	)
+	for _, span := range find(doc) {
+		du, err := datauri.DecodeString(doc[span[0]:span[1]])
+		if err != nil {
+			continue
+		}
+		s, err := fn(du)
+		if err != nil {
+			return fmt.Errorf("extract: data URI at offset %d: %w", span[0], err)
+		}
+		out.WriteString(doc[last:span[0]])
+		out.WriteString(quote(s, doc[span[0]-1]))
+		last = span[1]
+	}
+	out.WriteString(doc[last:])
+	_, err = io.WriteString(w, out.String())
+	return err
+}
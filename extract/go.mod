@@ -0,0 +1,8 @@
+module github.com/invopop/datauri/extract
+
+go 1.22.2
+
+require (
+	github.com/invopop/datauri v0.0.0-20261016113131-7f1f5f6e23bd
+	golang.org/x/net v0.35.0
+)
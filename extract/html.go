@@ -0,0 +1,138 @@
+package extract
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// voidElements are the HTML elements without content nor end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// ExtractHTML returns the Data URIs found in the HTML document read from
+// r, in the src, href and srcset attributes, and in the url() values of
+// the style attributes, in the order of the document. The Data URIs which
+// fail to decode are returned too, with their error.
+func ExtractHTML(r io.Reader) ([]Found, error) {
+	var (
+		z      = html.NewTokenizer(r)
+		found  []Found
+		stack  []string
+		offset int
+	)
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return found, nil
+		}
+		raw := string(z.Raw())
+		start := offset
+		offset += len(raw)
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			path := strings.Join(append(stack, tok.Data), "/")
+			if tt == html.StartTagToken && !voidElements[tok.Data] {
+				stack = append(stack, tok.Data)
+			}
+			from := 0
+			for _, attr := range tok.Attr {
+				for _, s := range attrDataURIs(attr) {
+					f := newFound(s)
+					f.Path, f.Attr, f.Offset = path, attr.Key, start
+					if i := strings.Index(raw[from:], s); i >= 0 {
+						f.Offset += from + i
+						from += i + len(s)
+					}
+					found = append(found, f)
+				}
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == string(name) {
+					stack = stack[:i]
+					break
+				}
+			}
+		}
+	}
+}
+
+// attrDataURIs returns the Data URIs held by attr.
+func attrDataURIs(attr html.Attribute) []string {
+	switch attr.Key {
+	case "src", "href":
+		if s := strings.TrimSpace(attr.Val); isDataURI(s) {
+			return []string{s}
+		}
+	case "srcset":
+		return srcsetDataURIs(attr.Val)
+	case "style":
+		var uris []string
+		for _, span := range cssURLs(attr.Val) {
+			uris = append(uris, attr.Val[span[0]:span[1]])
+		}
+		return uris
+	}
+	return nil
+}
+
+// srcsetDataURIs returns the Data URIs of the image candidates of srcset,
+// parsed as by browsers: a URL is a run of non-space characters, so that
+// the commas of Data URIs are kept, without its trailing commas.
+func srcsetDataURIs(srcset string) []string {
+	var uris []string
+	s := srcset
+	for {
+		s = strings.TrimLeft(s, " \t\n\f\r,")
+		if s == "" {
+			return uris
+		}
+		end := strings.IndexAny(s, " \t\n\f\r")
+		if end < 0 {
+			end = len(s)
+		}
+		url := s[:end]
+		s = s[end:]
+		if trimmed := strings.TrimRight(url, ","); len(trimmed) < len(url) {
+			// no descriptors
+			url = trimmed
+		} else {
+			s = skipDescriptors(s)
+		}
+		if isDataURI(url) {
+			uris = append(uris, url)
+		}
+	}
+}
+
+// skipDescriptors returns s after the descriptors of an image candidate,
+// up to the comma ending them, outside of parentheses.
+func skipDescriptors(s string) string {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				return s[i+1:]
+			}
+		}
+	}
+	return ""
+}
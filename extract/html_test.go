@@ -0,0 +1,79 @@
+package extract
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testHTML = `<!DOCTYPE html>
+<html>
+<head>
+<link rel="icon" href="data:image/x-icon;base64,AAAB">
+</head>
+<body>
+<div style="background: url('data:image/png;base64,aGV5YQ==') no-repeat">
+<img src="data:image/gif;base64,R0lGODlh" srcset="data:image/png;base64,aGV5YQ== 1x, /logo@2x.png 2x, data:image/png;base64,aGV5YQ==, data:,two 3x">
+</div>
+<p><a href="https://example.com">link</a><img src="data:text/plain;base64,%%%"/></p>
+<img src="data:text/plain,a&amp;b">
+</body>
+</html>`
+
+func TestExtractHTML(t *testing.T) {
+	found, err := ExtractHTML(strings.NewReader(testHTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		Raw, Path, Attr string
+		OK              bool
+	}{
+		{"data:image/x-icon;base64,AAAB", "html/head/link", "href", true},
+		{"data:image/png;base64,aGV5YQ==", "html/body/div", "style", true},
+		{"data:image/gif;base64,R0lGODlh", "html/body/div/img", "src", true},
+		{"data:image/png;base64,aGV5YQ==", "html/body/div/img", "srcset", true},
+		{"data:image/png;base64,aGV5YQ==", "html/body/div/img", "srcset", true},
+		{"data:,two", "html/body/div/img", "srcset", true},
+		{"data:text/plain;base64,%%%", "html/body/p/img", "src", false},
+		{"data:text/plain,a&b", "html/body/img", "src", true},
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("Expected %d Data URIs, got %d: %v", len(expected), len(found), found)
+	}
+	for i, f := range found {
+		e := expected[i]
+		got := []any{f.Raw, f.Path, f.Attr, f.Err == nil}
+		if want := []any{e.Raw, e.Path, e.Attr, e.OK}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: expected %v, got %v", i, want, got)
+		}
+		if f.Err == nil && f.DataURI == nil {
+			t.Errorf("%d: expected a DataURI", i)
+		}
+		if e.Raw != "data:text/plain,a&b" && !strings.HasPrefix(testHTML[f.Offset:], f.Raw) {
+			t.Errorf("%d: expected %s at offset %d, got %.20s", i, f.Raw, f.Offset, testHTML[f.Offset:])
+		}
+	}
+	if last := found[len(found)-1]; !strings.HasPrefix(testHTML[last.Offset:], "<img") {
+		t.Errorf("Expected the offset of the start tag, got %.20s", testHTML[last.Offset:])
+	}
+}
+
+func TestSrcsetDataURIs(t *testing.T) {
+	tests := []struct {
+		Srcset   string
+		Expected []string
+	}{
+		{"", nil},
+		{"a.png 1x, b.png 2x", nil},
+		{"data:,a", []string{"data:,a"}},
+		{"data:,a 1x,data:,b 2x", []string{"data:,a", "data:,b"}},
+		{"data:,a,, data:,b", []string{"data:,a", "data:,b"}},
+		{"a.png (max-width: 1px, x) 1x, data:,b", []string{"data:,b"}},
+	}
+	for _, test := range tests {
+		if got := srcsetDataURIs(test.Srcset); !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("%q: expected %v, got %v", test.Srcset, test.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package extract
+
+import (
+	"io"
+	"strings"
+
+	"github.com/invopop/datauri"
+)
+
+// ExtractMarkdown returns the Data URIs found in the destinations of the
+// inline images of the Markdown document read from r, like
+// ![alt](data:image/png;base64,...), in the order of the document.
+// Fenced and indented code blocks, and code spans, are skipped. The Data URIs which fail to
+// decode are returned too, with their error.
+func ExtractMarkdown(r io.Reader) ([]Found, error) {
+	return extractAll(r, markdownURLs)
+}
+
+// RewriteMarkdown copies the Markdown document read from r to w, with the
+// Data URIs of its inline images replaced by what fn returns for them,
+// as RewriteCSS does, e.g. to externalize embedded images. The replacement
+// is written between angle brackets when needed.
+func RewriteMarkdown(r io.Reader, w io.Writer, fn func(*datauri.DataURI) (string, error)) error {
+	return rewrite(r, w, markdownURLs, quoteDestination, fn)
+}
+
+// quoteDestination returns s written as the destination of a link,
+// after prev, the byte preceding the replaced destination.
+func quoteDestination(s string, prev byte) string {
+	if prev == '<' {
+		return escapeMarkdown(s, "<>\n")
+	}
+	if strings.ContainsAny(s, " \t\n<>") || !balancedParens(s) {
+		return "<" + escapeMarkdown(s, "<>\n") + ">"
+	}
+	return s
+}
+
+func escapeMarkdown(s, chars string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '\n' {
+			b.WriteString("%0A")
+		} else {
+			if c == '\\' || strings.IndexByte(chars, c) >= 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func balancedParens(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// markdownURLs returns the spans in md of the Data URIs of the
+// destinations of its inline images, skipping code spans, fenced code
+// blocks, closed by a fence at least as long as the opening one, and
+// indented code blocks, which cannot interrupt a paragraph. List items
+// are not told apart: a line of a list item indented by four spaces,
+// after a blank line, is skipped as an indented code block.
+func markdownURLs(md string) [][2]int {
+	var (
+		spans     [][2]int
+		fence     string
+		paragraph bool // the previous line continues a paragraph
+	)
+	for lineStart := 0; lineStart < len(md); {
+		lineEnd := strings.IndexByte(md[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(md)
+		} else {
+			lineEnd += lineStart + 1
+		}
+		line := md[lineStart:lineEnd]
+		switch {
+		case fence != "":
+			if closesFence(line, fence) {
+				fence = ""
+			}
+		case strings.TrimSpace(line) == "":
+			paragraph = false
+		case indentation(line) >= 4 && !paragraph:
+			// indented code block
+		default:
+			if fence = openingFence(line); fence != "" {
+				paragraph = false
+				break
+			}
+			for _, span := range lineImageURLs(line) {
+				spans = append(spans, [2]int{lineStart + span[0], lineStart + span[1]})
+			}
+			paragraph = !strings.HasPrefix(strings.TrimLeft(line, " "), "#")
+		}
+		lineStart = lineEnd
+	}
+	return spans
+}
+
+// indentation returns the width of the leading spaces and tabs of line,
+// a tab advancing to the next multiple of four.
+func indentation(line string) int {
+	width := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// openingFence returns the fence opening a fenced code block on line,
+// a run of at least three backticks or tildes indented by up to three
+// spaces, or "" if line opens none.
+func openingFence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || trimmed == "" || trimmed[0] != '`' && trimmed[0] != '~' {
+		return ""
+	}
+	n := 1
+	for n < len(trimmed) && trimmed[n] == trimmed[0] {
+		n++
+	}
+	if n < 3 || trimmed[0] == '`' && strings.IndexByte(trimmed[n:], '`') >= 0 {
+		// the info string of a backtick fence holds no backtick
+		return ""
+	}
+	return trimmed[:n]
+}
+
+// closesFence reports whether line closes the fenced code block opened
+// by fence: it holds a run of the same character, at least as long,
+// indented by up to three spaces, and nothing else but spaces.
+func closesFence(line, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == fence[0] {
+		n++
+	}
+	return n >= len(fence) && strings.TrimSpace(trimmed[n:]) == ""
+}
+
+// lineImageURLs returns the spans in line of the Data URIs
+// of the destinations of its inline images.
+func lineImageURLs(line string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '`':
+			// code span
+			n := 1
+			for i+n < len(line) && line[i+n] == '`' {
+				n++
+			}
+			ticks := line[i : i+n]
+			if end := strings.Index(line[i+n:], ticks); end >= 0 {
+				i += n + end + n - 1
+			} else {
+				i += n - 1
+			}
+		case '!':
+			if i+1 < len(line) && line[i+1] == '[' {
+				if span, end, ok := imageDestination(line, i+1); ok {
+					if isDataURI(line[span[0]:span[1]]) {
+						spans = append(spans, span)
+					}
+					i = end - 1
+				}
+			}
+		}
+	}
+	return spans
+}
+
+// imageDestination returns the span of the destination of the image
+// whose label starts at i, and the end of the image.
+func imageDestination(line string, i int) (span [2]int, end int, ok bool) {
+	// label, with balanced brackets
+	depth := 0
+	for ; i < len(line); i++ {
+		if c := line[i]; c == '\\' {
+			i++
+		} else if c == '[' {
+			depth++
+		} else if c == ']' {
+			if depth--; depth == 0 {
+				break
+			}
+		}
+	}
+	if i+1 >= len(line) || line[i+1] != '(' {
+		return span, 0, false
+	}
+	i += 2
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	if i < len(line) && line[i] == '<' {
+		start := i + 1
+		for i = start; i < len(line) && line[i] != '>' && line[i] != '\n'; i++ {
+			if line[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(line) || line[i] != '>' {
+			return span, 0, false
+		}
+		return [2]int{start, i}, i + 1, true
+	}
+	start := i
+	depth = 0
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ')' && depth == 0 {
+			break
+		}
+		switch c {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	i = min(i, len(line))
+	return [2]int{start, i}, i, true
+}
@@ -0,0 +1,94 @@
+package extract
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/invopop/datauri"
+)
+
+const testMarkdown = "# Logo\n" +
+	"![logo](data:image/png;base64,aGV5YQ==) and ![icon](<data:image/svg+xml,<svg/\\>> \"Icon\")\n" +
+	"![a [nested] label](data:text/plain,(a)b \"title\") [link](data:,notanimage)\n" +
+	"`![code](data:,span)` \\![escaped](data:,x)\n" +
+	"```\n![fenced](data:,block)\n```\n" +
+	"![remote](https://example.com/a.png) ![bad](data:;base64,%%%)\n"
+
+func TestExtractMarkdown(t *testing.T) {
+	found, err := ExtractMarkdown(strings.NewReader(testMarkdown))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		Raw string
+		OK  bool
+	}{
+		{"data:image/png;base64,aGV5YQ==", true},
+		{`data:image/svg+xml,<svg/\>`, true},
+		{"data:text/plain,(a)b", true},
+		{"data:;base64,%%%", false},
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("Expected %d Data URIs, got %d: %v", len(expected), len(found), found)
+	}
+	for i, f := range found {
+		if f.Raw != expected[i].Raw || (f.Err == nil) != expected[i].OK {
+			t.Errorf("%d: expected %v, got %v", i, expected[i], f)
+		}
+		if !strings.HasPrefix(testMarkdown[f.Offset:], f.Raw) {
+			t.Errorf("%d: expected %s at offset %d, got %.20s", i, f.Raw, f.Offset, testMarkdown[f.Offset:])
+		}
+	}
+}
+
+func TestRewriteMarkdown(t *testing.T) {
+	var n int
+	var b strings.Builder
+	err := RewriteMarkdown(strings.NewReader(testMarkdown), &b, func(du *datauri.DataURI) (string, error) {
+		n++
+		if n == 3 {
+			return "my images/(3.txt", nil
+		}
+		ext, _ := du.SuggestedExtension()
+		return fmt.Sprintf("images/%d%s", n, ext), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Logo\n" +
+		"![logo](images/1.png) and ![icon](<images/2.svg> \"Icon\")\n" +
+		"![a [nested] label](<my images/(3.txt> \"title\") [link](data:,notanimage)\n" +
+		"`![code](data:,span)` \\![escaped](data:,x)\n" +
+		"```\n![fenced](data:,block)\n```\n" +
+		"![remote](https://example.com/a.png) ![bad](data:;base64,%%%)\n"
+	if b.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, b.String())
+	}
+}
+
+func TestMarkdownURLsCodeBlocks(t *testing.T) {
+	tests := []struct {
+		Markdown string
+		Expected []string
+	}{
+		{"````\n```\n![a](data:,fenced)\n````\n![b](data:,y)\n", []string{"data:,y"}},
+		{"~~~\n```\n![a](data:,fenced)\n~~~~ \n![b](data:,y)\n", []string{"data:,y"}},
+		{"```\n![a](data:,fenced)\n``` not closed\n![b](data:,fenced)\n", nil},
+		{"``` a `code` span ![a](data:,x)\n", []string{"data:,x"}},
+		{"text\n\n    ![a](data:,indented)\n\t![b](data:,indented)\n![c](data:,y)\n", []string{"data:,y"}},
+		{"# Title\n    ![a](data:,indented)\n", nil},
+		{"a paragraph\n    ![a](data:,x)\n", []string{"data:,x"}},
+		{"    ```\n![a](data:,x)\n", []string{"data:,x"}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, span := range markdownURLs(test.Markdown) {
+			got = append(got, test.Markdown[span[0]:span[1]])
+		}
+		if !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("%q: expected %v, got %v", test.Markdown, test.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package datauri
+
+import (
+	"io/fs"
+	"os"
+)
+
+// EncodeFile reads the file at path and encodes it into a Data URI
+// string, using base 64 encoding, with opts, e.g. WithoutMetadata.
+// Its media type is detected with DetectWithHints, from its content
+// and the extension of path.
+func EncodeFile(path string, opts ...EncodeOption) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return newDetected(data, path).StringWith(opts...), nil
+}
+
+// WriteFile writes the decoded payload of du to the file at path,
+// creating it with perm (before umask) if needed, like os.WriteFile.
+func (du *DataURI) WriteFile(path string, perm fs.FileMode) error {
+	return os.WriteFile(path, du.Data, perm)
+}
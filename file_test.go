@@ -0,0 +1,55 @@
+package datauri
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncodeFileWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name                string
+		data                []byte
+		expectedContentType string
+	}{
+		{"a.png", []byte("\x89PNG\x0d\x0a\x1a\x0a"), "image/png"},
+		{"a.txt", []byte("heya"), "text/plain"},
+		{"a.css", []byte("body{}"), "text/css"},
+		{"a", nil, "text/plain"},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := os.WriteFile(path, test.data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		s, err := EncodeFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		du, err := DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if du.ContentType() != test.expectedContentType {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expectedContentType, du.ContentType())
+		}
+
+		out := filepath.Join(dir, "out-"+test.name)
+		if err := du.WriteFile(out, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, test.data) {
+			t.Errorf("Expected %q, got %q", test.data, data)
+		}
+	}
+
+	if _, err := EncodeFile(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package datauri
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// findChunkSize is the size of the reads of FindAllReader.
+const findChunkSize = 32 << 10
+
+// Match is a Data URI found in a text by FindAll.
+type Match struct {
+	// DataURI is the decoded Data URI.
+	DataURI *DataURI
+	// Start and End are the offsets in the text of the first byte
+	// of the Data URI and of the byte following it.
+	Start, End int
+}
+
+// FindAll returns the valid Data URIs found in the text s, such as logs or
+// JSON documents, in order. The header of a candidate is checked with the
+// lexer of the decoder: it ends at the data comma, or at the first
+// character which is not allowed in a URL, or is a quote or a closing
+// parenthesis, out of a quoted parameter value, which may hold any
+// character but a line break. Then the candidate is decoded: its payload
+// ends at the first character which is not allowed in a URL, or is a quote
+// or a closing parenthesis, and a base64 payload ends at the first
+// character which is not base64, of the standard or the URL-safe
+// alphabet, unless it is "%", in which case the payload is not valid.
+// The "data:" prefix must not follow a character of a URI scheme, so
+// that "metadata:" is not a Data URI.
+//
+// Candidates which fail to decode are skipped, see FindAllChecked
+// to collect their errors.
+func FindAll(s string) []Match {
+	matches, _ := findAll(s, nil, nil)
+	return matches
+}
+
+// FindAllChecked is like FindAll, but decoding the candidates with opts,
+// and reporting those which fail to decode. By default, it aborts at the
+// first failure and returns it as a *BatchError, whose Index is the offset
+// of the candidate in s. With WithErrorBudget, it goes on and returns the
+// Data URIs found along with the errors of the failed candidates, as
+// BatchErrors.
+func FindAllChecked(s string, opts ...Option) ([]Match, error) {
+	o := newOptions(opts)
+	if o.err != nil {
+		return nil, o.err
+	}
+	var errs BatchErrors
+	matches, err := findAll(s, opts, func(offset int, err error) error {
+		errs = append(errs, &BatchError{Index: offset, Err: err})
+		return errs.exceeded(o.errorBudget)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return matches, errs
+	}
+	return matches, nil
+}
+
+// findAll returns the Data URIs found in s, decoded with opts. onError,
+// if not nil, is called with the offset and the error of each failed
+// candidate, and aborts the search if it returns an error.
+func findAll(s string, opts []Option, onError func(offset int, err error) error) ([]Match, error) {
+	var matches []Match
+	for i := 0; ; {
+		j := strings.Index(s[i:], dataPrefix)
+		if j < 0 {
+			return matches, nil
+		}
+		// the search resumes after the prefix of a rejected candidate
+		start := i + j
+		i = start + len(dataPrefix)
+		if start > 0 && isSchemeChar(s[start-1]) {
+			continue
+		}
+		du, n, err := matchDataURI(s[start:], opts)
+		if err != nil {
+			if onError != nil {
+				if err := onError(start, err); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		matches = append(matches, Match{DataURI: du, Start: start, End: start + n})
+		i = start + n
+	}
+}
+
+// FindAllReader is like FindAll, for the text read from r, with the
+// offsets of the Data URIs in the stream. r is read in chunks, and only
+// the text which may still be part of a Data URI is kept in memory.
+func FindAllReader(r io.Reader) ([]Match, error) {
+	var matches []Match
+	err := scanText(r, func(s string, offset int) error {
+		for _, m := range FindAll(s) {
+			m.Start += offset
+			m.End += offset
+			matches = append(matches, m)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// ReplaceAllFunc copies the text read from r to w, with the Data URIs found
+// in it, see FindAll, replaced by what fn returns for them, e.g. to redact,
+// recompress or externalize them. The text is streamed, only the text which
+// may still be part of a Data URI is kept in memory. It stops at the first
+// error of fn, returned with the offset of the Data URI.
+//
+// Candidates which fail to decode are copied as is, see
+// ReplaceAllFuncChecked to collect their errors.
+func ReplaceAllFunc(r io.Reader, w io.Writer, fn func(*DataURI) (string, error)) error {
+	return replaceAll(r, w, fn, nil, nil)
+}
+
+// ReplaceAllFuncChecked is like ReplaceAllFunc, but decoding the candidates
+// with opts, and reporting those which fail to decode, as FindAllChecked:
+// by default, it stops at the first failure, and with WithErrorBudget, it
+// goes on, copying the failed candidates as is, and returns their errors
+// as BatchErrors, once all the text is copied.
+func ReplaceAllFuncChecked(r io.Reader, w io.Writer, fn func(*DataURI) (string, error), opts ...Option) error {
+	o := newOptions(opts)
+	if o.err != nil {
+		return o.err
+	}
+	var errs BatchErrors
+	err := replaceAll(r, w, fn, opts, func(offset int, err error) error {
+		errs = append(errs, &BatchError{Index: offset, Err: err})
+		return errs.exceeded(o.errorBudget)
+	})
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// replaceAll implements ReplaceAllFunc and ReplaceAllFuncChecked,
+// onError being called as by findAll, with the offset in the stream.
+func replaceAll(r io.Reader, w io.Writer, fn func(*DataURI) (string, error), opts []Option, onError func(offset int, err error) error) error {
+	return scanText(r, func(s string, offset int) error {
+		var onSegmentError func(int, error) error
+		if onError != nil {
+			onSegmentError = func(start int, err error) error {
+				return onError(offset+start, err)
+			}
+		}
+		matches, err := findAll(s, opts, onSegmentError)
+		if err != nil {
+			return err
+		}
+		last := 0
+		for _, m := range matches {
+			repl, err := fn(m.DataURI)
+			if err != nil {
+				return fmt.Errorf("datauri: data URI at offset %d: %w", offset+m.Start, err)
+			}
+			if _, err := io.WriteString(w, s[last:m.Start]+repl); err != nil {
+				return err
+			}
+			last = m.End
+		}
+		_, err = io.WriteString(w, s[last:])
+		return err
+	})
+}
+
+// scanText reads the text of r in chunks, and calls fn with its segments
+// and their offset, each segment ending with a character which cannot be
+// part of a Data URI, but the last one, so that no Data URI spans two
+// segments.
+func scanText(r io.Reader, fn func(s string, offset int) error) error {
+	var (
+		pending []byte
+		offset  int
+		scanned int // bytes of pending already looked for a cut
+		chunk   = make([]byte, findChunkSize)
+	)
+	for {
+		n, err := r.Read(chunk)
+		pending = append(pending, chunk[:n]...)
+		if err == io.EOF {
+			if len(pending) == 0 {
+				return nil
+			}
+			return fn(string(pending), offset)
+		}
+		if err != nil {
+			return err
+		}
+		// only the bytes just read are scanned, so that a Data URI
+		// spanning many chunks is not scanned again for each of them
+		i := len(pending) - 1
+		for i >= scanned && isEmbeddedURLChar(rune(pending[i])) {
+			i--
+		}
+		if i < scanned {
+			scanned = len(pending)
+			continue
+		}
+		scanned = len(pending)
+		if i = cutBeforeOpenHeader(pending, i); i < 0 {
+			continue
+		}
+		if err := fn(string(pending[:i+1]), offset); err != nil {
+			return err
+		}
+		offset += i + 1
+		scanned -= i + 1
+		pending = append(pending[:0], pending[i+1:]...)
+	}
+}
+
+// cutBeforeOpenHeader returns i, the offset of the byte of b the text
+// can be cut after, unless the header of the last Data URI of b before i,
+// with a quoted parameter value, is still open at i: it returns the offset
+// of the byte preceding that Data URI then, -1 if there is none.
+func cutBeforeOpenHeader(b []byte, i int) int {
+	from := max(0, i+1-maxHeaderSize)
+	if k := bytes.LastIndexAny(b[from:i+1], "\r\n"); k >= 0 {
+		from += k + 1
+	}
+	j := bytes.LastIndex(b[from:i+1], []byte(dataPrefix))
+	if j < 0 {
+		return i
+	}
+	j += from
+	if dataCommaIndex(string(b[j:i+1])) >= 0 {
+		return i
+	}
+	return j - 1
+}
+
+// headerLen returns the length of the header candidate at the start of s,
+// up to and including the data comma if any, see FindAll.
+func headerLen(s string) int {
+	inQuote := false
+	for i := 0; i < min(len(s), maxHeaderSize); i++ {
+		switch c := s[i]; {
+		case c == '\r' || c == '\n':
+			return i
+		case inQuote && c == '\\':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == dataComma:
+			return i + 1
+		case !isEmbeddedURLChar(rune(c)):
+			return i
+		}
+	}
+	return min(len(s), maxHeaderSize)
+}
+
+// matchDataURI returns the Data URI at the start of the text s, decoded
+// with opts, and its length, or the error of the candidate if s does not
+// start with a valid Data URI.
+func matchDataURI(s string, opts []Option) (*DataURI, int, error) {
+	var (
+		header = s[:headerLen(s)]
+		l      = lex(header)
+		base64 bool
+	)
+	for {
+		it, ok := l.nextItem()
+		if !ok || it.t == itemError || it.t == itemEOF {
+			return nil, 0, headerError(header)
+		}
+		if it.t == itemBase64Enc {
+			base64 = true
+		}
+		if it.t == itemDataComma {
+			break
+		}
+	}
+	end := l.start
+	if base64 {
+		for end < len(s) && isBase64Char(s[end]) {
+			end++
+		}
+		if end < len(s) && s[end] == '%' {
+			// an escaped payload
+			return nil, 0, newParseError(s, end, ErrInvalidData, "invalid character in base64 data", nil)
+		}
+	} else {
+		for end < len(s) && isEmbeddedURLChar(rune(s[end])) {
+			end++
+		}
+	}
+	du, err := DecodeString(s[:end], opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return du, end, nil
+}
+
+// isBase64Char reports whether c is a character of base64, of either
+// alphabet, as accepted by the decoder.
+func isBase64Char(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("+/-_=", c) >= 0
+}
+
+// isSchemeChar reports whether c can be part of a URI scheme (RFC 3986).
+func isSchemeChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("+-.", c) >= 0
+}
@@ -0,0 +1,223 @@
+package datauri
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+const findText = `2024-03-01 upload {"logo":"data:image/png;base64,aGV5YQ==","note":"data:text/plain,hi%20there"}
+metadata:,notadatauri data:image/png;base64,aGV5YQ==. data:;base64,%%% (data:,paren) data:text/plain;charset=utf-8,x data:nocomma`
+
+func TestFindAll(t *testing.T) {
+	expected := []struct {
+		Raw  string
+		Data string
+	}{
+		{"data:image/png;base64,aGV5YQ==", "heya"},
+		{"data:text/plain,hi%20there", "hi there"},
+		{"data:image/png;base64,aGV5YQ==", "heya"},
+		{"data:,paren", "paren"},
+		{`data:text/plain;charset=utf-8,x`, "x"},
+	}
+	check := func(name string, matches []Match) {
+		if len(matches) != len(expected) {
+			t.Fatalf("%s: expected %d matches, got %d: %v", name, len(expected), len(matches), matches)
+		}
+		for i, m := range matches {
+			if raw := findText[m.Start:m.End]; raw != expected[i].Raw {
+				t.Errorf("%s %d: expected %s, got %s", name, i, expected[i].Raw, raw)
+			}
+			if string(m.DataURI.Data) != expected[i].Data {
+				t.Errorf("%s %d: expected %s, got %s", name, i, expected[i].Data, m.DataURI.Data)
+			}
+		}
+	}
+	check("FindAll", FindAll(findText))
+
+	matches, err := FindAllReader(iotest.OneByteReader(strings.NewReader(findText)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("FindAllReader", matches)
+
+	matches = FindAll("metadata:data:text/plain,hi")
+	if len(matches) != 1 || matches[0].Start != len("metadata:") || string(matches[0].DataURI.Data) != "hi" {
+		t.Errorf("Expected the Data URI following metadata:, got %v", matches)
+	}
+
+	if matches := FindAll("no data URI here"); matches != nil {
+		t.Errorf("Expected no match, got %v", matches)
+	}
+}
+
+func TestFindAllURLSafeBase64(t *testing.T) {
+	tests := []struct {
+		Text     string
+		Expected string // empty for no match
+	}{
+		{"x=data:;base64,SGk-", "data:;base64,SGk-"},
+		{"x=data:;base64,PDw_Pz4-&y=1", "data:;base64,PDw_Pz4-"},
+		{"x=data:;base64,PDw_Pz4+", ""},
+		{"x=data:;base64,SGk%3D", ""},
+	}
+	for _, test := range tests {
+		matches := FindAll(test.Text)
+		switch {
+		case test.Expected == "" && len(matches) != 0:
+			t.Errorf("%s: expected no match, got %v", test.Text, matches)
+		case test.Expected != "" && (len(matches) != 1 || test.Text[matches[0].Start:matches[0].End] != test.Expected):
+			t.Errorf("%s: expected %s, got %v", test.Text, test.Expected, matches)
+		}
+	}
+}
+
+func TestFindAllQuotedParam(t *testing.T) {
+	text := `data:text/plain;name="a,b";base64,aGV5YQ== <img src='data:text/plain;name="a b",heya'> data:;a="b
+c",x`
+	expected := []string{
+		`data:text/plain;name="a,b";base64,aGV5YQ==`,
+		`data:text/plain;name="a b",heya`,
+	}
+	check := func(name string, matches []Match) {
+		if len(matches) != len(expected) {
+			t.Fatalf("%s: expected %d matches, got %d: %v", name, len(expected), len(matches), matches)
+		}
+		for i, m := range matches {
+			if raw := text[m.Start:m.End]; raw != expected[i] {
+				t.Errorf("%s %d: expected %s, got %s", name, i, expected[i], raw)
+			}
+		}
+	}
+	check("FindAll", FindAll(text))
+
+	matches, err := FindAllReader(iotest.OneByteReader(strings.NewReader(text)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("FindAllReader", matches)
+}
+
+func TestFindAllChecked(t *testing.T) {
+	invalid := strings.Index(findText, "data:;base64,%%%")
+	noComma := strings.Index(findText, "data:nocomma")
+
+	_, err := FindAllChecked(findText)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != invalid {
+		t.Errorf("Expected failure at offset %d, got %v", invalid, err)
+	}
+	if !errors.Is(err, ErrInvalidData) {
+		t.Errorf("Expected the error to wrap ErrInvalidData, got %v", err)
+	}
+
+	matches, err := FindAllChecked(findText, WithErrorBudget(2))
+	var errs BatchErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected BatchErrors, got %v", err)
+	}
+	if len(errs) != 2 || errs[0].Index != invalid || errs[1].Index != noComma {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+	if !errors.Is(errs[1], ErrMissingComma) {
+		t.Errorf("Expected the error to wrap ErrMissingComma, got %v", errs[1])
+	}
+	if len(matches) != len(FindAll(findText)) {
+		t.Errorf("Expected %d matches, got %d", len(FindAll(findText)), len(matches))
+	}
+
+	if _, err := FindAllChecked(findText, WithErrorBudget(1)); !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("Expected to abort after 2 errors, got %v", err)
+	}
+
+	matches, err = FindAllChecked(findText, WithErrorBudget(10), WithAllowedMediaTypes("image/*"))
+	var v Violation
+	if !errors.As(err, &errs) || len(errs) != 5 || !errors.As(errs[0], &v) || v.Code != CodeMediaTypeNotAllowed {
+		t.Errorf("Expected 5 errors, got %v", err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("Expected 2 matches, got %v", matches)
+	}
+
+	if matches, err := FindAllChecked("no data URI here"); matches != nil || err != nil {
+		t.Errorf("Expected no match, got %v, %v", matches, err)
+	}
+}
+
+func TestReplaceAllFuncLargeDataURI(t *testing.T) {
+	payload := strings.Repeat("aGV5", 1<<21)
+	text := "<img src=\"data:image/png;base64," + payload + "\"> and <img src=\"data:,x\">"
+	start := time.Now()
+	var b strings.Builder
+	// read in small chunks, so that the Data URI spans many of them
+	err := ReplaceAllFunc(iotest.HalfReader(&chunkedReader{strings.NewReader(text), 4 << 10}), &b, func(du *DataURI) (string, error) {
+		return fmt.Sprintf("%s %d bytes", du.ContentType(), len(du.Data)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<img src="image/png 6291456 bytes"> and <img src="text/plain 1 bytes">`
+	if b.String() != expected {
+		t.Errorf("Expected %s, got %.100s", expected, b.String())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the text to be scanned in linear time, took %v", elapsed)
+	}
+}
+
+// chunkedReader reads at most n bytes at a time from r.
+type chunkedReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	return c.r.Read(p[:min(len(p), c.n)])
+}
+
+func TestReplaceAllFunc(t *testing.T) {
+	var b strings.Builder
+	err := ReplaceAllFunc(iotest.HalfReader(strings.NewReader(findText)), &b, func(du *DataURI) (string, error) {
+		return fmt.Sprintf("<%s %d bytes>", du.ContentType(), len(du.Data)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `2024-03-01 upload {"logo":"<image/png 4 bytes>","note":"<text/plain 8 bytes>"}
+metadata:,notadatauri <image/png 4 bytes>. data:;base64,%%% (<text/plain 5 bytes>) <text/plain 1 bytes> data:nocomma`
+	if b.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, b.String())
+	}
+
+	b.Reset()
+	err = ReplaceAllFuncChecked(iotest.HalfReader(strings.NewReader(findText)), &b, func(du *DataURI) (string, error) {
+		return fmt.Sprintf("<%s %d bytes>", du.ContentType(), len(du.Data)), nil
+	}, WithErrorBudget(2))
+	var errs BatchErrors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[1].Index != strings.Index(findText, "data:nocomma") {
+		t.Errorf("Unexpected errors %v", err)
+	}
+	if b.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, b.String())
+	}
+	if err := ReplaceAllFuncChecked(strings.NewReader(findText), io.Discard, func(du *DataURI) (string, error) {
+		return "", nil
+	}); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("Expected %v, got %v", ErrInvalidData, err)
+	}
+
+	errRedact := errors.New("redacted")
+	err = ReplaceAllFunc(strings.NewReader(findText), &b, func(du *DataURI) (string, error) {
+		return "", errRedact
+	})
+	if !errors.Is(err, errRedact) {
+		t.Errorf("Expected %v, got %v", errRedact, err)
+	}
+	if expected := "datauri: data URI at offset 27: redacted"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,35 @@
+package datauri
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	flagsMu sync.RWMutex
+	// flags maps bare parameter flags, such as the non-standard ";utf8",
+	// to the attribute and value they stand for.
+	flags = map[string][2]string{
+		"utf8":  {"charset", "utf-8"},
+		"utf-8": {"charset", "utf-8"},
+	}
+)
+
+// RegisterFlag makes the decoder accept the bare parameter flag
+// (a parameter without a value, as in "data:text/plain;utf8,...")
+// as a shorthand for the attribute=value parameter.
+// Flags are matched case-insensitively.
+//
+// "utf8" and "utf-8" are registered by default, as "charset=utf-8".
+func RegisterFlag(flag, attribute, value string) {
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+	flags[strings.ToLower(flag)] = [2]string{attribute, value}
+}
+
+func lookupFlag(flag string) (attribute, value string, ok bool) {
+	flagsMu.RLock()
+	defer flagsMu.RUnlock()
+	p, ok := flags[strings.ToLower(flag)]
+	return p[0], p[1], ok
+}
@@ -0,0 +1,44 @@
+package datauri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	RegisterFlag("X-Compressed", "compression", "yes")
+	tests := []struct {
+		Input          string
+		ExpectedErr    bool
+		ExpectedParams map[string]string
+		ExpectedData   string
+	}{
+		{`data:text/plain;utf8,heya`, false, map[string]string{"charset": "utf-8"}, "heya"},
+		{`data:text/plain;UTF-8,heya`, false, map[string]string{"charset": "utf-8"}, "heya"},
+		{`data:text/plain;utf8;base64,aGV5YQ==`, false, map[string]string{"charset": "utf-8"}, "heya"},
+		{`data:text/plain;utf8;name=foo,heya`, false, map[string]string{"charset": "utf-8", "name": "foo"}, "heya"},
+		{`data:text/plain;x-compressed;utf8,heya`, false, map[string]string{"charset": "utf-8", "compression": "yes"}, "heya"},
+		{`data:;utf8,heya`, false, map[string]string{"charset": "utf-8"}, "heya"},
+		{`data:text/plain;unknown,heya`, true, nil, ""},
+		{`data:text/plain;base64;utf8,aGV5YQ==`, true, nil, ""},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.Input)
+		if test.ExpectedErr {
+			if err == nil {
+				t.Errorf("%s: expected error", test.Input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.Input, err)
+			continue
+		}
+		if !reflect.DeepEqual(du.Params, test.ExpectedParams) {
+			t.Errorf("%s: expected params %v, got %v", test.Input, test.ExpectedParams, du.Params)
+		}
+		if string(du.Data) != test.ExpectedData {
+			t.Errorf("%s: expected data %q, got %q", test.Input, test.ExpectedData, du.Data)
+		}
+	}
+}
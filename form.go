@@ -0,0 +1,26 @@
+package datauri
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrNotForm is returned when reading the form values of a Data URI
+// whose media type is not application/x-www-form-urlencoded.
+var ErrNotForm = errors.New("datauri: not an application/x-www-form-urlencoded")
+
+// FromFormValues returns a DataURI of media type
+// application/x-www-form-urlencoded whose payload is v encoded.
+func FromFormValues(v url.Values) *DataURI {
+	return New([]byte(v.Encode()), "application/x-www-form-urlencoded")
+}
+
+// FormValues parses the payload of an application/x-www-form-urlencoded
+// du, or returns ErrNotForm for any other media type.
+func (du *DataURI) FormValues() (url.Values, error) {
+	if !strings.EqualFold(du.Type, "application") || !strings.EqualFold(du.Subtype, "x-www-form-urlencoded") {
+		return nil, ErrNotForm
+	}
+	return url.ParseQuery(string(du.Data))
+}
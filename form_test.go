@@ -0,0 +1,48 @@
+package datauri
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFormValues(t *testing.T) {
+	v := url.Values{
+		"event": {"payment.succeeded"},
+		"id":    {"42"},
+		"tags":  {"a b", "c&d"},
+	}
+	du, err := DecodeString(FromFormValues(v).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if du.ContentType() != "application/x-www-form-urlencoded" {
+		t.Errorf("Expected %s, got %s", "application/x-www-form-urlencoded", du.ContentType())
+	}
+	got, err := du.FormValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("Expected %v, got %v", v, got)
+	}
+
+	du, err = DecodeString(`data:application/x-www-form-urlencoded,a=1&b=2%252B3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := url.Values{"a": {"1"}, "b": {"2+3"}}
+	if got, err := du.FormValues(); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v, %v", expected, got, err)
+	}
+}
+
+func TestFormValuesErrors(t *testing.T) {
+	if _, err := New([]byte("a=1"), "text/plain").FormValues(); !errors.Is(err, ErrNotForm) {
+		t.Errorf("Expected %v, got %v", ErrNotForm, err)
+	}
+	if _, err := New([]byte("a=%zz"), "application/x-www-form-urlencoded").FormValues(); err == nil {
+		t.Error("Expected error")
+	}
+}
@@ -0,0 +1,84 @@
+package datauri
+
+import (
+	"strings"
+)
+
+// JoinFragments reassembles the string literals of source code that
+// are split across lines, so that the data URIs they hold can be
+// extracted whole. It is a heuristic working on Go, JavaScript, Python
+// or C-like sources: a literal closed by a quote, followed by a line
+// break and a literal opened with the same quote, optionally joined
+// with a '+' operator, is merged into a single literal. For example:
+//
+//	img := "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAA" +
+//		"AAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
+//
+// becomes:
+//
+//	img := "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
+//
+// Escape sequences in the literals are left as is.
+func JoinFragments(src string) string {
+	var (
+		b     strings.Builder
+		quote byte // quote of the current literal, or 0
+		last  int  // start of the text not written to b yet
+	)
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote == 0:
+			if c == '"' || c == '\'' || c == '`' {
+				quote = c
+			}
+		case c == '\\' && quote != '`':
+			i++
+		case c == quote:
+			end := fragmentJoint(src, i)
+			if end < 0 {
+				quote = 0
+				continue
+			}
+			b.WriteString(src[last:i])
+			last = end + 1
+			i = end
+		case c == '\n' && quote != '`':
+			// unterminated literal, resynchronize
+			quote = 0
+		}
+	}
+	if last == 0 {
+		return src
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
+
+// fragmentJoint returns the index of the opening quote of the literal
+// following the literal closed at src[i], if they are separated by
+// a line break and an optional '+' operator only, or -1.
+func fragmentJoint(src string, i int) int {
+	quote := src[i]
+	plus, newline := false, false
+	for j := i + 1; j < len(src); j++ {
+		switch c := src[j]; c {
+		case ' ', '\t', '\r':
+		case '\n':
+			newline = true
+		case '+':
+			if plus {
+				return -1
+			}
+			plus = true
+		case quote:
+			if !newline {
+				return -1
+			}
+			return j
+		default:
+			return -1
+		}
+	}
+	return -1
+}
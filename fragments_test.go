@@ -0,0 +1,61 @@
+package datauri
+
+import (
+	"testing"
+)
+
+func TestJoinFragments(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected string
+	}{
+		{
+			"img := \"data:image/png;base64,aGV5\" +\n\t\"YQ==\"\n",
+			"img := \"data:image/png;base64,aGV5YQ==\"\n",
+		},
+		{
+			"const img = 'data:,A%20' +\r\n  'brief' +\n  '%20note';",
+			"const img = 'data:,A%20brief%20note';",
+		},
+		{
+			"img = (\"data:,A%20\"\n       \"brief\")",
+			"img = (\"data:,A%20brief\")",
+		},
+		{
+			"const img = \"data:,A%20\"\n  + \"brief\";",
+			"const img = \"data:,A%20brief\";",
+		},
+		{
+			"s := `data:,a` +\n\t`b`",
+			"s := `data:,ab`",
+		},
+		{
+			"s := \"a\\\"\" +\n\t\"b\"",
+			"s := \"a\\\"b\"",
+		},
+		// not split across lines
+		{
+			`s := "a" + "b"`,
+			`s := "a" + "b"`,
+		},
+		// different quotes
+		{
+			"s = \"a\" +\n 'b'",
+			"s = \"a\" +\n 'b'",
+		},
+		// not a concatenation
+		{
+			"f(\"a\",\n \"b\")",
+			"f(\"a\",\n \"b\")",
+		},
+		{
+			"s := \"a\" + b +\n\t\"c\"",
+			"s := \"a\" + b +\n\t\"c\"",
+		},
+	}
+	for _, test := range tests {
+		if got := JoinFragments(test.Input); got != test.Expected {
+			t.Errorf("Expected %q, got %q", test.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package datauri
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS is a read-only, in-memory filesystem holding the decoded payloads
+// of Data URIs as files, see NewFS. It implements fs.FS, fs.ReadFileFS,
+// fs.ReadDirFS and fs.StatFS, so that it can be used with http.FS,
+// template.ParseFS or fs.WalkDir.
+type FS struct {
+	// ModTime is the modification time of the files and directories,
+	// e.g. the time the Data URIs were stored, used by http.FileServer
+	// for the Last-Modified header. It is the zero time by default.
+	ModTime time.Time
+
+	files map[string]*DataURI
+}
+
+// NewFS returns an FS holding the decoded payloads of dus as files, named
+// after the keys of dus with the extension of their media type added when
+// they have none, as Archive does. Keys may hold slashes, making
+// directories. Keys which are not valid paths, see fs.ValidPath, are
+// ignored. The size of the files is the size of the decoded payloads.
+//
+// dus must not be modified while the FS is in use.
+func NewFS(dus map[string]*DataURI) *FS {
+	fsys := &FS{files: make(map[string]*DataURI, len(dus))}
+	for name, du := range dus {
+		if name = archiveName(name, du); fs.ValidPath(name) && name != "." {
+			fsys.files[name] = du
+		}
+	}
+	return fsys
+}
+
+// Open implements the fs.FS interface.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if du, ok := fsys.files[name]; ok {
+		return &dataFile{
+			info:   fsys.fileInfo(name, du),
+			Reader: bytes.NewReader(du.Data),
+		}, nil
+	}
+	entries := fsys.readDir(name)
+	if entries == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &dataDir{
+		info:    fsys.dirInfo(name),
+		entries: entries,
+	}, nil
+}
+
+// ReadFile implements the fs.ReadFileFS interface.
+func (fsys *FS) ReadFile(name string) ([]byte, error) {
+	du, ok := fsys.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), du.Data...), nil
+}
+
+// ReadDir implements the fs.ReadDirFS interface.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries := fsys.readDir(name)
+	if entries == nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+// Stat implements the fs.StatFS interface.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if du, ok := fsys.files[name]; ok {
+		return fsys.fileInfo(name, du), nil
+	}
+	if fsys.readDir(name) == nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return fsys.dirInfo(name), nil
+}
+
+func (fsys *FS) fileInfo(name string, du *DataURI) fileInfo {
+	return fileInfo{name: path.Base(name), size: int64(len(du.Data)), modTime: fsys.ModTime}
+}
+
+func (fsys *FS) dirInfo(name string) fileInfo {
+	return fileInfo{name: path.Base(name), mode: fs.ModeDir | 0o555, modTime: fsys.ModTime}
+}
+
+// readDir returns the entries of the directory name, sorted by name,
+// or nil if there is no such directory.
+func (fsys *FS) readDir(dir string) []fs.DirEntry {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+	seen := make(map[string]bool)
+	var entries []fs.DirEntry
+	for name, du := range fsys.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		elem, sub, isDir := strings.Cut(rest, "/")
+		if seen[elem] {
+			continue
+		}
+		seen[elem] = true
+		if isDir && sub != "" {
+			entries = append(entries, fs.FileInfoToDirEntry(fsys.dirInfo(elem)))
+		} else {
+			entries = append(entries, fs.FileInfoToDirEntry(fsys.fileInfo(elem, du)))
+		}
+	}
+	if entries == nil && dir == "." {
+		return []fs.DirEntry{}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+// dataFile is an open file of an FS.
+type dataFile struct {
+	info fileInfo
+	*bytes.Reader
+}
+
+func (f *dataFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *dataFile) Close() error { return nil }
+
+// dataDir is an open directory of an FS.
+type dataDir struct {
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dataDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+func (d *dataDir) Close() error { return nil }
+
+func (d *dataDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements the fs.ReadDirFile interface.
+func (d *dataDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
+
+// fileInfo implements fs.FileInfo for the files and directories of an FS.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (fi fileInfo) Name() string { return fi.name }
+
+func (fi fileInfo) Size() int64 { return fi.size }
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.mode == 0 {
+		return 0o444
+	}
+	return fi.mode
+}
+
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+
+func (fi fileInfo) IsDir() bool { return fi.mode.IsDir() }
+
+func (fi fileInfo) Sys() any { return nil }
@@ -0,0 +1,77 @@
+package datauri
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNewFS(t *testing.T) {
+	fsys := NewFS(map[string]*DataURI{
+		"logo":           New([]byte("\x89PNG\x0d\x0a\x1a\x0a"), "image/png"),
+		"notes.md":       New([]byte("# Notes"), "text/markdown"),
+		"css/main":       New([]byte("body{}"), "text/css"),
+		"css/print.css":  New([]byte("@media print{}"), "text/css"),
+		"a/b/c/data":     New([]byte{0, 1, 2}, "application/octet-stream"),
+		"../escape":      New([]byte("no"), "text/plain"),
+		"/absolute.txt":  New([]byte("no"), "text/plain"),
+		"css/empty.json": New(nil, "application/json"),
+	})
+	expected := []string{"logo.png", "notes.md", "css/main.css", "css/print.css", "a/b/c/data.bin", "css/empty.json"}
+	if err := fstest.TestFS(fsys, expected...); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(fsys, "css/main.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "body{}" {
+		t.Errorf("Expected %s, got %s", "body{}", data)
+	}
+	if _, err := fsys.Open("../escape"); err == nil {
+		t.Error("Expected an error for an invalid path")
+	}
+	if _, err := fs.Stat(fsys, "missing.txt"); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	if err := fstest.TestFS(NewFS(nil)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSModTime(t *testing.T) {
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fsys := NewFS(map[string]*DataURI{
+		"img/logo": New([]byte("\x89PNG\x0d\x0a\x1a\x0a"), "image/png"),
+	})
+	fsys.ModTime = modTime
+	if err := fstest.TestFS(fsys, "img/logo.png"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"img/logo.png", "img", "."} {
+		fi, err := fsys.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(modTime) {
+			t.Errorf("%s: expected %v, got %v", name, modTime, fi.ModTime())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	http.FileServer(http.FS(fsys)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/img/logo.png", nil))
+	if got := rec.Header().Get("Last-Modified"); got != modTime.Format(http.TimeFormat) {
+		t.Errorf("Expected %s, got %s", modTime.Format(http.TimeFormat), got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "8" {
+		t.Errorf("Expected %s, got %s", "8", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Expected %s, got %s", "image/png", got)
+	}
+}
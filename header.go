@@ -0,0 +1,70 @@
+package datauri
+
+import (
+	"strings"
+)
+
+// dataCommaIndex returns the index in s of the comma separating the
+// header of a Data URI from its payload, skipping commas inside quoted
+// parameter values. It returns -1 if there is none.
+func dataCommaIndex(s string) int {
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inQuote && c == '\\':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case !inQuote && c == dataComma:
+			return i
+		}
+	}
+	return -1
+}
+
+// IsDataURI reports whether s looks like a Data URI, with the "data:"
+// prefix and the comma preceding the payload, e.g. to tell inline data
+// from a URL to fetch. It is cheap, s is not decoded: use IsValidDataURI
+// to check that it is valid.
+func IsDataURI(s string) bool {
+	return strings.HasPrefix(s, dataPrefix) && dataCommaIndex(s) >= 0
+}
+
+// IsValidDataURI reports whether s is a Data URI which DecodeString
+// decodes with opts, see Validate.
+func IsValidDataURI(s string, opts ...Option) bool {
+	return IsDataURI(s) && Validate(s, opts...) == nil
+}
+
+// DecodeHeader decodes the header of the Data URI s, up to the comma,
+// without decoding its payload, e.g. to route Data URIs by media type.
+// It returns the media type and the encoding of the payload, and the
+// offset in s of the payload, which follows the comma.
+// Like DecodeString, it sets the default media type when s has none.
+func DecodeHeader(s string) (mt MediaType, encoding string, payloadOffset int, err error) {
+	du, payload, err := decodeHeader(s)
+	if err != nil {
+		return MediaType{}, "", 0, err
+	}
+	return du.MediaType, du.Encoding, len(s) - len(payload), nil
+}
+
+// decodeHeader decodes the header of the Data URI s, without its payload.
+// It returns the DataURI described by the header, with empty Data,
+// and the payload that follows the comma, still encoded.
+func decodeHeader(s string) (*DataURI, string, error) {
+	i := -1
+	if strings.HasPrefix(s, dataPrefix) {
+		i = dataCommaIndex(s)
+	}
+	if i < 0 {
+		// let the parser report the most accurate error
+		_, err := DecodeString(s)
+		return nil, "", err
+	}
+	du, err := DecodeString(s[:i+1])
+	if err != nil {
+		return nil, "", err
+	}
+	return du, s[i+1:], nil
+}
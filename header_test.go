@@ -0,0 +1,69 @@
+package datauri
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeHeader(t *testing.T) {
+	tests := []struct {
+		InputString   string
+		MediaType     string
+		Encoding      string
+		PayloadOffset int
+	}{
+		{"data:,heya", "text/plain;charset=US-ASCII", EncodingASCII, 6},
+		{"data:image/png;base64,iVBORw0KGgo=", "image/png", EncodingBase64, 22},
+		{`data:text/plain;name="a,b",heya`, "text/plain;name=a%2Cb", EncodingASCII, 27},
+		{"data:text/plain;base64,%%%", "text/plain", EncodingBase64, 23},
+	}
+	for _, test := range tests {
+		mt, encoding, offset, err := DecodeHeader(test.InputString)
+		if err != nil {
+			t.Errorf("%s: %v", test.InputString, err)
+			continue
+		}
+		if got := mt.String(); got != test.MediaType {
+			t.Errorf("%s: expected %s, got %s", test.InputString, test.MediaType, got)
+		}
+		if encoding != test.Encoding || offset != test.PayloadOffset {
+			t.Errorf("%s: expected %s at %d, got %s at %d", test.InputString, test.Encoding, test.PayloadOffset, encoding, offset)
+		}
+	}
+
+	if _, _, _, err := DecodeHeader("data:text/plain"); !errors.Is(err, ErrMissingComma) {
+		t.Errorf("Expected %v, got %v", ErrMissingComma, err)
+	}
+	if _, _, _, err := DecodeHeader("data:te(xt/plain;base64,aGV5YQ=="); !errors.Is(err, ErrInvalidMediaType) {
+		t.Errorf("Expected %v, got %v", ErrInvalidMediaType, err)
+	}
+}
+
+func TestIsDataURI(t *testing.T) {
+	tests := []struct {
+		InputString string
+		IsDataURI   bool
+		IsValid     bool
+	}{
+		{"data:,heya", true, true},
+		{"data:image/png;base64,iVBORw0KGgo=", true, true},
+		{`data:text/plain;name="a,b"`, false, false},
+		{"data:text/plain;base64,%%%", true, false},
+		{"data:te(xt/plain,a", true, false},
+		{"data:text/plain", false, false},
+		{"https://example.com/logo.png", false, false},
+		{"DATA:,heya", false, false},
+		{"", false, false},
+	}
+	for _, test := range tests {
+		if got := IsDataURI(test.InputString); got != test.IsDataURI {
+			t.Errorf("%s: expected %v, got %v", test.InputString, test.IsDataURI, got)
+		}
+		if got := IsValidDataURI(test.InputString); got != test.IsValid {
+			t.Errorf("%s: expected valid %v, got %v", test.InputString, test.IsValid, got)
+		}
+	}
+	if IsValidDataURI("data:,heya", WithAllowedMediaTypes("image/*")) {
+		t.Error("Expected a Data URI rejected by its options to be invalid")
+	}
+}
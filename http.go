@@ -0,0 +1,115 @@
+package datauri
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// DecodeRequest decodes the Data URI in the body of r with opts,
+// and closes it. Use WithMaxDataSize to bound the payload, or Middleware
+// to bound the body too.
+func DecodeRequest(r *http.Request, opts ...Option) (*DataURI, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, errors.New("datauri: empty request body")
+	}
+	defer r.Body.Close() //nolint:errcheck
+	return Decode(r.Body, opts...)
+}
+
+// Middleware returns a middleware decoding the Data URI in the body of
+// the requests with DecodeRequest, before calling the next handler with
+// the DataURI in the context of the request, see FromContext.
+// Bodies larger than maxBytes are rejected, whatever the options.
+//
+// Requests whose body fails to decode are replied with an error:
+// 413 Request Entity Too Large for bodies or payloads too large,
+// 415 Unsupported Media Type for media types not allowed by
+// WithAllowedMediaTypes, and 400 Bad Request otherwise.
+func Middleware(maxBytes int64, opts ...Option) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			du, err := DecodeRequest(r, opts...)
+			if err != nil {
+				http.Error(w, err.Error(), requestErrorStatus(err))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), du)))
+		})
+	}
+}
+
+// requestErrorStatus returns the status code of the reply to
+// a request whose body failed to decode with err.
+func requestErrorStatus(err error) int {
+	var (
+		maxErr *http.MaxBytesError
+		v      Violation
+	)
+	switch {
+	case errors.As(err, &maxErr), errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &v) && v.Code == CodeMediaTypeNotAllowed:
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx holding du, see FromContext.
+func NewContext(ctx context.Context, du *DataURI) context.Context {
+	return context.WithValue(ctx, contextKey{}, du)
+}
+
+// FromContext returns the DataURI held by ctx, as stored by Middleware
+// or NewContext, and whether there is one.
+func FromContext(ctx context.Context) (*DataURI, bool) {
+	du, ok := ctx.Value(contextKey{}).(*DataURI)
+	return du, ok
+}
+
+// ServeDataURI replies to r with the payload of du, with:
+//   - its media type, with its parameters, as Content-Type,
+//   - the size of the payload as Content-Length,
+//   - a strong ETag derived from the SHA-256 hash of the payload.
+//
+// Range and conditional requests are handled by http.ServeContent.
+// A nil du is replied with http.NotFound.
+func ServeDataURI(w http.ResponseWriter, r *http.Request, du *DataURI) {
+	if du == nil {
+		http.NotFound(w, r)
+		return
+	}
+	h := w.Header()
+	h.Set("Content-Type", contentTypeHeader(&du.MediaType))
+	sum := sha256.Sum256(du.Data)
+	h.Set("Etag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(du.Data))
+}
+
+// contentTypeHeader returns mt formatted for a Content-Type header,
+// with its parameters in quoted strings when needed, rather than
+// escaped as in Data URIs.
+func contentTypeHeader(mt *MediaType) string {
+	m := withDefaultParams(*mt)
+	if m.Type == "" && m.Subtype == "" {
+		m.Type, m.Subtype = "text", "plain"
+		if len(m.Params) == 0 {
+			m.Params = map[string]string{"charset": "US-ASCII"}
+		}
+	}
+	if ct := mime.FormatMediaType(m.ContentType(), m.Params); ct != "" {
+		return ct
+	}
+	// parameters which can't be formatted are dropped
+	return m.ContentType()
+}
@@ -0,0 +1,136 @@
+package datauri
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeDataURI(t *testing.T) {
+	du := New([]byte("A brief note"), "text/plain", "charset", "utf-8", "name", "a note.txt")
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		ServeDataURI(rec, req, du)
+		return rec
+	}
+
+	rec := serve(nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	expectedType := `text/plain; charset=utf-8; name="a note.txt"`
+	if got := rec.Header().Get("Content-Type"); got != expectedType {
+		t.Errorf("Expected %s, got %s", expectedType, got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "12" {
+		t.Errorf("Expected %s, got %s", "12", got)
+	}
+	if got := rec.Body.String(); got != "A brief note" {
+		t.Errorf("Expected %s, got %s", "A brief note", got)
+	}
+	etag := rec.Header().Get("Etag")
+	if len(etag) != 34 {
+		t.Errorf("Expected a strong ETag, got %s", etag)
+	}
+
+	rec = serve(http.Header{"Range": {"bytes=2-6"}})
+	if rec.Code != http.StatusPartialContent {
+		t.Errorf("Expected %d, got %d", http.StatusPartialContent, rec.Code)
+	}
+	if got := rec.Body.String(); got != "brief" {
+		t.Errorf("Expected %s, got %s", "brief", got)
+	}
+
+	rec = serve(http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected %d, got %d", http.StatusNotModified, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	ServeDataURI(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestContentTypeHeader(t *testing.T) {
+	tests := []struct {
+		MediaType MediaType
+		Expected  string
+	}{
+		{MediaType{Type: "image", Subtype: "png"}, "image/png"},
+		{MediaType{}, "text/plain; charset=US-ASCII"},
+		{MediaType{Params: map[string]string{"charset": "utf-8"}}, "text/plain; charset=utf-8"},
+		{MediaType{Type: "text", Subtype: "plain", Params: map[string]string{"title": "café"}}, "text/plain; title*=utf-8''caf%C3%A9"},
+	}
+	for _, test := range tests {
+		if got := contentTypeHeader(&test.MediaType); got != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, got)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := Middleware(1<<10, WithAllowedMediaTypes("image/*"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		du, ok := FromContext(r.Context())
+		if !ok {
+			t.Error("Expected a DataURI in the context")
+			return
+		}
+		fmt.Fprint(w, du.ContentType())
+	}))
+	tests := []struct {
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+	}{
+		{"data:image/png;base64,aGV5YQ==", http.StatusOK, "image/png"},
+		{"data:image/png;base64," + strings.Repeat("A", 2<<10), http.StatusRequestEntityTooLarge, ""},
+		{"data:text/plain,heya", http.StatusUnsupportedMediaType, ""},
+		{"data:image/png;base64", http.StatusBadRequest, ""},
+		{"", http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.Body)))
+		if rec.Code != test.ExpectedCode {
+			t.Errorf("%.30s: expected %d, got %d", test.Body, test.ExpectedCode, rec.Code)
+		}
+		if test.ExpectedBody != "" && rec.Body.String() != test.ExpectedBody {
+			t.Errorf("%.30s: expected %s, got %s", test.Body, test.ExpectedBody, rec.Body.String())
+		}
+	}
+
+	if _, ok := FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); ok {
+		t.Error("Expected no DataURI in the context")
+	}
+}
+
+func TestDecodeRequestTooLarge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(New(make([]byte, 100), "image/png").String()))
+	rec := httptest.NewRecorder()
+	Middleware(1<<10, WithMaxDataSize(10))(http.NotFoundHandler()).ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+}
+
+func ExampleMiddleware() {
+	upload := func(w http.ResponseWriter, r *http.Request) {
+		du, _ := FromContext(r.Context())
+		fmt.Fprintf(w, "%s: %d bytes", du.ContentType(), len(du.Data))
+	}
+	handler := Middleware(1<<20, WithAllowedMediaTypes("image/*"))(http.HandlerFunc(upload))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("data:image/png;base64,aGV5YQ=="))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	fmt.Println(rec.Body.String())
+	// Output: image/png: 4 bytes
+}
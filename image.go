@@ -0,0 +1,40 @@
+package datauri
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Image decodes the payload of du, of an image media type, with
+// image.Decode, and returns the image and the name of its format,
+// e.g. "png". As usual, the image formats must be registered, e.g. by
+// importing image/png, or the typedimage package for PNG, JPEG and GIF.
+func (du *DataURI) Image() (image.Image, string, error) {
+	if err := du.checkImage(); err != nil {
+		return nil, "", err
+	}
+	return image.Decode(bytes.NewReader(du.Data))
+}
+
+// Dimensions returns the width and height of the image held by du,
+// with image.DecodeConfig, without decoding the whole image, e.g. to
+// validate uploaded images cheaply.
+func (du *DataURI) Dimensions() (w, h int, err error) {
+	if err := du.checkImage(); err != nil {
+		return 0, 0, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(du.Data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+func (du *DataURI) checkImage() error {
+	if !strings.EqualFold(du.Type, "image") {
+		return fmt.Errorf("datauri: %s is not an image", du.ContentType())
+	}
+	return nil
+}
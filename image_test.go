@@ -0,0 +1,46 @@
+package datauri
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 3))); err != nil {
+		t.Fatal(err)
+	}
+	du := New(buf.Bytes(), "image/png")
+
+	img, format, err := du.Image()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "png" {
+		t.Errorf("Expected %s, got %s", "png", format)
+	}
+	if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 3 {
+		t.Errorf("Expected %v, got %v", image.Rect(0, 0, 2, 3), img.Bounds())
+	}
+	w, h, err := du.Dimensions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != 2 || h != 3 {
+		t.Errorf("Expected 2x3, got %dx%d", w, h)
+	}
+
+	for _, du := range []*DataURI{
+		New(buf.Bytes(), "text/plain"),
+		New([]byte("not an image"), "image/png"),
+	} {
+		if _, _, err := du.Image(); err == nil {
+			t.Errorf("%s: expected an error", du.ContentType())
+		}
+		if _, _, err := du.Dimensions(); err == nil {
+			t.Errorf("%s: expected an error", du.ContentType())
+		}
+	}
+}
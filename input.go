@@ -0,0 +1,54 @@
+package datauri
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+)
+
+// The functions below are those of the strings and bytes packages
+// used on the inputs of the lexer, whatever their type.
+// The type switches on a pointer to s do not make it escape.
+
+func indexByte[T input](s T, c byte) int {
+	switch s := any(&s).(type) {
+	case *string:
+		return strings.IndexByte(*s, c)
+	case *[]byte:
+		return bytes.IndexByte(*s, c)
+	}
+	panic("unreachable")
+}
+
+func countByte[T input](s T, c byte) int {
+	switch s := any(&s).(type) {
+	case *string:
+		return strings.Count(*s, string(c))
+	case *[]byte:
+		return bytes.Count(*s, []byte{c})
+	}
+	panic("unreachable")
+}
+
+func hasSuffix[T input](s T, suffix string) bool {
+	return len(s) >= len(suffix) && string(s[len(s)-len(suffix):]) == suffix
+}
+
+// trimLineBreaks returns s without its trailing line breaks.
+func trimLineBreaks[T input](s T) T {
+	for len(s) > 0 && (s[len(s)-1] == '\r' || s[len(s)-1] == '\n') {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// decodeBase64 decodes s with enc into dst, which must be large enough.
+func decodeBase64[T input](enc *base64.Encoding, dst []byte, s T) (int, error) {
+	switch s := any(&s).(type) {
+	case *string:
+		return enc.Decode(dst, stringToBytes(*s))
+	case *[]byte:
+		return enc.Decode(dst, *s)
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,160 @@
+package datauri
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MarshalJSON implements the json.Marshaler interface, writing du as
+// a JSON string, or null for the zero DataURI. It has a value receiver
+// for fields of type DataURI to be marshaled in any struct value.
+func (du DataURI) MarshalJSON() ([]byte, error) {
+	if du.IsZero() {
+		return []byte("null"), nil
+	}
+	txt, err := du.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(txt))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, reading
+// a JSON string as a Data URI. As usual, null leaves du unmodified.
+func (du *DataURI) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return du.UnmarshalText([]byte(s))
+}
+
+// DecodeJSONString decodes the Data URI held by the JSON string literal b,
+// quotes included, as found when scraping Data URIs out of JSON documents.
+// Literals without escape sequences, the most common ones, are lexed
+// in place, and the others once unescaped in a single pass.
+func DecodeJSONString(b []byte, opts ...Option) (*DataURI, error) {
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return nil, errNotJSONString
+	}
+	inner := b[1 : len(b)-1]
+	if isPlainJSONString(inner) {
+		return DecodeBytes(inner, opts...)
+	}
+	unescaped, err := appendUnescapedJSON(make([]byte, 0, len(inner)), inner)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBytes(unescaped, opts...)
+}
+
+var errNotJSONString = errors.New("datauri: not a JSON string literal")
+
+// isPlainJSONString reports whether b is the content of a JSON string
+// literal without escape sequences.
+func isPlainJSONString(b []byte) bool {
+	for _, c := range b {
+		if c == '\\' || c == '"' || c < 0x20 {
+			return false
+		}
+	}
+	return true
+}
+
+// appendUnescapedJSON appends to dst the content b of a JSON string
+// literal, its escape sequences unescaped as by json.Unmarshal.
+func appendUnescapedJSON(dst, b []byte) ([]byte, error) {
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c == '"' || c < 0x20 {
+			return nil, errNotJSONString
+		}
+		if c != '\\' {
+			dst = append(dst, c)
+			continue
+		}
+		if i++; i == len(b) {
+			return nil, errNotJSONString
+		}
+		switch c = b[i]; c {
+		case '"', '\\', '/':
+			dst = append(dst, c)
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r, ok := unhexRune(b[i+1:])
+			if !ok {
+				return nil, errNotJSONString
+			}
+			i += 4
+			if utf16.IsSurrogate(r) {
+				// a pair, or a lone surrogate replaced as by json.Unmarshal
+				var r2 rune
+				if len(b) > i+2 && b[i+1] == '\\' && b[i+2] == 'u' {
+					r2, _ = unhexRune(b[i+3:])
+				}
+				if r = utf16.DecodeRune(r, r2); r != unicode.ReplacementChar {
+					i += 6
+				}
+			}
+			dst = utf8.AppendRune(dst, r)
+		default:
+			return nil, errNotJSONString
+		}
+	}
+	return dst, nil
+}
+
+// unhexRune returns the rune of the 4 hexadecimal digits b starts with.
+func unhexRune(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range b[:4] {
+		if !isHex(c) {
+			return 0, false
+		}
+		r = r<<4 | rune(unhex(c))
+	}
+	return r, true
+}
+
+// DecodeJSON unmarshals the payload of du into v, with json.Unmarshal,
+// once decoded as text according to its charset, see Text. The media type
+// of du must be application/json or have the +json suffix.
+func (du *DataURI) DecodeJSON(v any) error {
+	if !du.MediaType.Matches("application/json") && !du.MediaType.Matches("+json") {
+		return fmt.Errorf("datauri: cannot decode %s as JSON", du.ContentType())
+	}
+	text, err := du.Text()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(text), v)
+}
+
+// NewJSON returns a DataURI of media type application/json
+// whose payload is v marshaled with json.Marshal.
+func NewJSON(v any) (*DataURI, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return New(data, "application/json"), nil
+}
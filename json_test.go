@@ -0,0 +1,148 @@
+package datauri
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type jsonDoc struct {
+	Avatar DataURI  `json:"avatar"`
+	Banner *DataURI `json:"banner,omitempty"`
+	Empty  DataURI  `json:"empty"`
+}
+
+func TestJSON(t *testing.T) {
+	doc := jsonDoc{
+		Avatar: *New([]byte("heya"), "image/png"),
+		Banner: New([]byte("heya"), "text/plain", "charset", "utf-8"),
+	}
+	// by value, for MarshalJSON to be used on non-addressable fields
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"avatar":"data:image/png;base64,aGV5YQ==","banner":"data:text/plain;charset=utf-8;base64,aGV5YQ==","empty":null}`
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, b)
+	}
+
+	var got jsonDoc
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Avatar.String() != doc.Avatar.String() || got.Banner.String() != doc.Banner.String() {
+		t.Errorf("Expected %v, got %v", doc, got)
+	}
+	if !got.Empty.IsZero() {
+		t.Errorf("Expected zero DataURI, got %v", got.Empty)
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	for _, input := range []string{`{"avatar":42}`, `{"avatar":"data:text/plain"}`} {
+		var doc jsonDoc
+		if err := json.Unmarshal([]byte(input), &doc); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestDecodeJSONString(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected string
+	}{
+		{`"data:text/plain;base64,aGV5YQ=="`, "heya"},
+		{`"data:text\/plain;base64,aGV5YQ=="`, "heya"},
+		{`"data:,A%20brief%20note"`, "A brief note"},
+		{`"data:,\u0041%20brief"`, "A brief"},
+		{`"data:text/plain;name=\"a b\",heya"`, "heya"},
+		{`"data:text\u002Fplain;base64,aGV5YQ\u003d\u003d"`, "heya"},
+		{`"data:,a\\b"`, `a\b`},
+		{`"data:,%F0%9F%98%80\ud83d\ude00"`, "\U0001F600\U0001F600"},
+	}
+	for _, test := range tests {
+		du, err := DecodeJSONString([]byte(test.Input))
+		if err != nil {
+			t.Errorf("%s: %v", test.Input, err)
+			continue
+		}
+		if string(du.Data) != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, du.Data)
+		}
+	}
+
+	for _, input := range []string{``, `"`, `data:,heya`, `"data:,he"ya"`, `"data:text/plain"`,
+		`"data:,\x"`, `"data:,\u00"`, `"data:,he\"`, "\"data:,he\nya\""} {
+		if _, err := DecodeJSONString([]byte(input)); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestDecodeJSONStringAllocs(t *testing.T) {
+	uri := []byte(`data:text/plain;base64,aGV5YQ==`)
+	base := testing.AllocsPerRun(100, func() {
+		_, _ = DecodeBytes(uri)
+	})
+	tests := []struct {
+		Input string
+		Extra float64 // allocations on top of DecodeBytes
+	}{
+		{`"data:text/plain;base64,aGV5YQ=="`, 0},
+		{`"data:text\/plain;base64,aGV5YQ=="`, 1},
+	}
+	for _, test := range tests {
+		b := []byte(test.Input)
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _ = DecodeJSONString(b)
+		})
+		if allocs > base+test.Extra {
+			t.Errorf("%s: expected %v allocations, got %v", test.Input, base+test.Extra, allocs)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type point struct {
+		X, Y int
+		Name string
+	}
+	du, err := NewJSON(point{1, 2, "café"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "data:application/json;base64,eyJYIjoxLCJZIjoyLCJOYW1lIjoiY2Fmw6kifQ=="; du.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, du.String())
+	}
+
+	tests := []struct {
+		InputString string
+		Expected    point
+		OK          bool
+	}{
+		{du.String(), point{1, 2, "café"}, true},
+		{`data:application/geo+json,{"X":3}`, point{X: 3}, true},
+		{`data:application/json;charset=iso-8859-1,{"Name":"caf%E9"}`, point{Name: "café"}, true},
+		{`data:text/plain,{"X":3}`, point{}, false},
+		{`data:application/json,{`, point{}, false},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.InputString)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got point
+		err = du.DecodeJSON(&got)
+		if (err == nil) != test.OK {
+			t.Errorf("%s: expected OK %v, got %v", test.InputString, test.OK, err)
+		}
+		if got != test.Expected {
+			t.Errorf("%s: expected %v, got %v", test.InputString, test.Expected, got)
+		}
+	}
+
+	if _, err := NewJSON(func() {}); err == nil {
+		t.Error("Expected an error for a value which cannot be marshaled")
+	}
+}
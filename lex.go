@@ -7,17 +7,27 @@ import (
 	"unicode/utf8"
 )
 
-type item struct {
+// input is the type of the inputs of the lexer: strings, and byte
+// slices, lexed as is rather than copied into a string.
+type input interface {
+	string | []byte
+}
+
+// lexItem is an item of an input of type T.
+type lexItem[T input] struct {
 	t   itemType
-	val string
+	val T
 }
 
-func (i item) String() string {
+// item is an item of a string input.
+type item = lexItem[string]
+
+func (i lexItem[T]) String() string {
 	switch i.t {
 	case itemEOF:
 		return "EOF"
 	case itemError:
-		return i.val
+		return string(i.val)
 	}
 	if len(i.val) > 10 {
 		return fmt.Sprintf("%.10q...", i.val)
@@ -42,6 +52,7 @@ const (
 	itemLeftStringQuote
 	itemRightStringQuote
 	itemParamVal
+	itemParamFlag
 
 	itemBase64Enc
 
@@ -78,13 +89,18 @@ func isTSpecialRune(r rune) bool {
 
 // See http://tools.ietf.org/html/rfc2045
 // This doesn't include extension-token case
-// as it's handled separatly
+// as it's handled separatly.
+// Media types are case-insensitive.
 func isDiscreteType(s string) bool {
+	s = strings.ToLower(s)
 	if strings.HasPrefix(s, "text") ||
 		strings.HasPrefix(s, "image") ||
 		strings.HasPrefix(s, "audio") ||
 		strings.HasPrefix(s, "video") ||
-		strings.HasPrefix(s, "application") {
+		strings.HasPrefix(s, "application") ||
+		// RFC 8081 and RFC 2077
+		strings.HasPrefix(s, "font") ||
+		strings.HasPrefix(s, "model") {
 		return true
 	}
 	return false
@@ -94,6 +110,7 @@ func isDiscreteType(s string) bool {
 // This doesn't include extension-token case
 // as it's handled separatly
 func isCompositeType(s string) bool {
+	s = strings.ToLower(s)
 	if strings.HasPrefix(s, "message") ||
 		strings.HasPrefix(s, "multipart") {
 		return true
@@ -125,67 +142,247 @@ func isURLCharRune(r rune) bool {
 		r != '`'
 }
 
-func isBase64Rune(r rune) bool {
-	return (r >= 'a' && r <= 'z') ||
-		(r >= 'A' && r <= 'Z') ||
-		(r >= '0' && r <= '9') ||
-		r == '+' ||
-		r == '/' ||
-		r == '=' ||
-		r == '\n'
+// urlCharTable is the lookup table of isURLCharRune for single bytes,
+// used to validate ASCII payloads, which make most of the input.
+// Non-ASCII runes are made of bytes above 0x7F, all rejected.
+var urlCharTable [256]bool
+
+func init() {
+	for i := range urlCharTable {
+		urlCharTable[i] = isURLCharRune(rune(i))
+	}
 }
 
-type stateFn func(*lexer) stateFn
+// hasPrefix reports whether s begins with prefix.
+func hasPrefix[T input](s T, prefix string) bool {
+	return len(s) >= len(prefix) && string(s[:len(prefix)]) == prefix
+}
 
-// lexer lexes the data URL scheme input string.
-// The implementation is from the text/template/parser package.
-type lexer struct {
-	input          string
-	start          int
-	pos            int
-	width          int
-	seenBase64Item bool
-	items          chan item
+// scanTable returns the index of the first byte of s
+// not accepted by table, or -1.
+func scanTable[T input](s T, table *[256]bool) int {
+	for i := 0; i < len(s); i++ {
+		if !table[s[i]] {
+			return i
+		}
+	}
+	return -1
 }
 
-func (l *lexer) run() {
-	for state := lexBeforeDataPrefix; state != nil; {
-		state = state(l)
+// lexState is a state of the lexer, run by step. The states are
+// an enum rather than function values, as instantiating a generic
+// function value allocates.
+type lexState uint8
+
+const (
+	stateEnd lexState = iota
+	stateBeforeDataPrefix
+	stateDataPrefix
+	stateAfterDataPrefix
+	stateXTokenMediaType
+	stateInDiscreteMediaType
+	stateMediaType
+	stateMediaSep
+	stateAfterMediaSep
+	stateMediaSubType
+	stateAfterMediaSubType
+	stateParamSemicolon
+	stateAfterParamSemicolon
+	stateBase64Enc
+	stateParamFlag
+	stateInParamAttr
+	stateParamAttr
+	stateParamEqual
+	stateAfterParamEqual
+	stateInQuotedStringParamVal
+	stateEscapedChar
+	stateInParamVal
+	stateQuotedStringParamVal
+	stateParamVal
+	stateAfterParamVal
+	stateDataComma
+	stateData
+)
+
+// lexer lexes the data URL scheme input, a string or a byte slice.
+// The implementation is from the text/template/parser package,
+// without its goroutine: the state functions are run synchronously,
+// in the goroutine of the caller of nextItem, until they emit items.
+type lexer[T input] struct {
+	input T
+	start int
+	pos   int
+	width int
+	state lexState
+	items []lexItem[T] // emitted and not yet returned by nextItem
+	buf   [2]lexItem[T]
+	err   error
+}
+
+// nextItem returns the next item of the input,
+// or false once all of them have been returned.
+func (l *lexer[T]) nextItem() (lexItem[T], bool) {
+	for len(l.items) == 0 {
+		if l.state == stateEnd {
+			return lexItem[T]{}, false
+		}
+		l.items = l.buf[:0]
+		l.state = l.step()
 	}
-	close(l.items)
+	it := l.items[0]
+	l.items = l.items[1:]
+	return it, true
+}
+
+// step runs the current state of l, and returns the next one.
+func (l *lexer[T]) step() lexState {
+	switch l.state {
+	case stateBeforeDataPrefix:
+		return lexBeforeDataPrefix(l)
+	case stateDataPrefix:
+		return lexDataPrefix(l)
+	case stateAfterDataPrefix:
+		return lexAfterDataPrefix(l)
+	case stateXTokenMediaType:
+		return lexXTokenMediaType(l)
+	case stateInDiscreteMediaType:
+		return lexInDiscreteMediaType(l)
+	case stateMediaType:
+		return lexMediaType(l)
+	case stateMediaSep:
+		return lexMediaSep(l)
+	case stateAfterMediaSep:
+		return lexAfterMediaSep(l)
+	case stateMediaSubType:
+		return lexMediaSubType(l)
+	case stateAfterMediaSubType:
+		return lexAfterMediaSubType(l)
+	case stateParamSemicolon:
+		return lexParamSemicolon(l)
+	case stateAfterParamSemicolon:
+		return lexAfterParamSemicolon(l)
+	case stateBase64Enc:
+		return lexBase64Enc(l)
+	case stateParamFlag:
+		return lexParamFlag(l)
+	case stateInParamAttr:
+		return lexInParamAttr(l)
+	case stateParamAttr:
+		return lexParamAttr(l)
+	case stateParamEqual:
+		return lexParamEqual(l)
+	case stateAfterParamEqual:
+		return lexAfterParamEqual(l)
+	case stateInQuotedStringParamVal:
+		return lexInQuotedStringParamVal(l)
+	case stateEscapedChar:
+		return lexEscapedChar(l)
+	case stateInParamVal:
+		return lexInParamVal(l)
+	case stateQuotedStringParamVal:
+		return lexQuotedStringParamVal(l)
+	case stateParamVal:
+		return lexParamVal(l)
+	case stateAfterParamVal:
+		return lexAfterParamVal(l)
+	case stateDataComma:
+		return lexDataComma(l)
+	case stateData:
+		return lexData(l)
+	}
+	return stateEnd
 }
 
-func (l *lexer) emit(t itemType) {
-	l.items <- item{t, l.input[l.start:l.pos]}
+func (l *lexer[T]) emit(t itemType) {
+	l.items = append(l.items, lexItem[T]{t, l.input[l.start:l.pos]})
 	l.start = l.pos
 }
 
-func (l *lexer) next() (r rune) {
+func (l *lexer[T]) next() (r rune) {
 	if l.pos >= len(l.input) {
 		l.width = 0
 		return eof
 	}
-	r, l.width = utf8.DecodeRuneInString(l.input[l.pos:])
+	if c := l.input[l.pos]; c < utf8.RuneSelf {
+		r, l.width = rune(c), 1
+	} else {
+		r, l.width = utf8.DecodeRuneInString(string(l.input[l.pos:min(l.pos+utf8.UTFMax, len(l.input))]))
+	}
 	l.pos += l.width
 	return r
 }
 
-func (l *lexer) backup() {
+func (l *lexer[T]) backup() {
 	l.pos -= l.width
 }
 
-func (l *lexer) errorf(format string, args ...interface{}) stateFn {
-	l.items <- item{itemError, fmt.Sprintf(format, args...)}
-	return nil
+// errorf emits an error of kind about the last rune read by next.
+func (l *lexer[T]) errorf(kind error, format string, args ...interface{}) lexState {
+	return l.errorAt(l.pos-l.width, kind, format, args...)
+}
+
+// truncatedf is like errorf, for inputs ending before the data comma.
+func (l *lexer[T]) truncatedf(format string, args ...interface{}) lexState {
+	return l.errorAt(len(l.input), ErrMissingComma, format, args...)
+}
+
+// errorAt emits an error of kind about the byte at offset.
+func (l *lexer[T]) errorAt(offset int, kind error, format string, args ...interface{}) lexState {
+	l.err = newParseError(l.input, offset, kind, fmt.Sprintf(format, args...), nil)
+	l.items = append(l.items, lexItem[T]{itemError, T(l.err.Error())})
+	return stateEnd
+}
+
+// ParseError is the error returned when decoding an invalid Data URI.
+// Its category, see the Err field, can be checked with errors.Is:
+//
+//	if errors.Is(err, datauri.ErrInvalidMediaType) { ... }
+//
+// Invalid payloads are also reported with their original error,
+// an *EscapeError or a base64.CorruptInputError, found by errors.As.
+type ParseError struct {
+	// Offset is the index in the input of the invalid byte, or the
+	// length of the input if it ends too early.
+	Offset int
+	// Got is the invalid byte, or 0 if the input ends too early.
+	Got byte
+	// Err is the category of the error: ErrNotDataURL,
+	// ErrInvalidMediaType, ErrInvalidParam or ErrInvalidData,
+	// or ErrMissingComma if the input ends before the data comma.
+	Err error
+
+	msg   string
+	cause error
+}
+
+func newParseError[T input](in T, offset int, kind error, msg string, cause error) *ParseError {
+	e := &ParseError{Offset: offset, Err: kind, msg: msg, cause: cause}
+	if offset < len(in) {
+		e.Got = in[offset]
+	}
+	return e
+}
+
+func (e *ParseError) Error() string {
+	if e.cause != nil {
+		return e.cause.Error()
+	}
+	return fmt.Sprintf("%s at offset %d", e.msg, e.Offset)
 }
 
-func lex(input string) *lexer {
-	l := &lexer{
-		input: input,
-		items: make(chan item),
+// Unwrap returns the category of e, and its original error if any.
+func (e *ParseError) Unwrap() []error {
+	if e.cause != nil {
+		return []error{e.Err, e.cause}
+	}
+	return []error{e.Err}
+}
+
+func lex[T input](s T) *lexer[T] {
+	return &lexer[T]{
+		input: s,
+		state: stateBeforeDataPrefix,
 	}
-	go l.run() // Concurrently run state machine.
-	return l
 }
 
 const (
@@ -196,322 +393,321 @@ const (
 	dataComma      = ','
 )
 
+// maxHeaderSize is the maximum size of a Data URI header, up to and
+// including the data comma, wherever the header is handled apart from
+// the payload: read by NewReader, Decode and OpenReaderAt, validated by
+// ValidatePrefix, searched by FindAll, and counted in the encoded size
+// Decode reads with WithMaxDataSize.
+const maxHeaderSize = 64 << 10
+
 // start lexing by detecting data prefix
-func lexBeforeDataPrefix(l *lexer) stateFn {
-	if strings.HasPrefix(l.input[l.pos:], dataPrefix) {
-		return lexDataPrefix
+func lexBeforeDataPrefix[T input](l *lexer[T]) lexState {
+	if hasPrefix(l.input[l.pos:], dataPrefix) {
+		return stateDataPrefix
 	}
-	return l.errorf("missing data prefix")
+	return l.errorf(ErrNotDataURL, "missing data prefix")
 }
 
 // lex data prefix
-func lexDataPrefix(l *lexer) stateFn {
+func lexDataPrefix[T input](l *lexer[T]) lexState {
 	l.pos += len(dataPrefix)
 	l.emit(itemDataPrefix)
-	return lexAfterDataPrefix
+	return stateAfterDataPrefix
 }
 
 // lex what's after data prefix.
 // it can be the media type/subtype separator,
 // the base64 encoding, or the comma preceding the data
-func lexAfterDataPrefix(l *lexer) stateFn {
+func lexAfterDataPrefix[T input](l *lexer[T]) lexState {
 	switch r := l.next(); {
 	case r == paramSemicolon:
 		l.backup()
-		return lexParamSemicolon
+		return stateParamSemicolon
 	case r == dataComma:
 		l.backup()
-		return lexDataComma
+		return stateDataComma
 	case r == eof:
-		return l.errorf("missing comma before data")
+		return l.truncatedf("missing comma before data")
 	case r == 'x' || r == 'X':
 		if l.next() == '-' {
-			return lexXTokenMediaType
+			return stateXTokenMediaType
 		}
-		return lexInDiscreteMediaType
+		return stateInDiscreteMediaType
 	case isTokenRune(r):
-		return lexInDiscreteMediaType
+		return stateInDiscreteMediaType
 	default:
-		return l.errorf("invalid character after data prefix")
+		return l.errorf(ErrInvalidMediaType, "invalid character after data prefix")
 	}
 }
 
-func lexXTokenMediaType(l *lexer) stateFn {
+func lexXTokenMediaType[T input](l *lexer[T]) lexState {
 	for {
 		switch r := l.next(); {
 		case r == mediaSep:
 			l.backup()
-			return lexMediaType
+			return stateMediaType
 		case r == eof:
-			return l.errorf("missing media type slash")
+			return l.truncatedf("missing media type slash")
 		case isTokenRune(r):
 		default:
-			return l.errorf("invalid character for media type")
+			return l.errorf(ErrInvalidMediaType, "invalid character for media type")
 		}
 	}
 }
 
-func lexInDiscreteMediaType(l *lexer) stateFn {
+func lexInDiscreteMediaType[T input](l *lexer[T]) lexState {
 	for {
 		switch r := l.next(); {
 		case r == mediaSep:
 			l.backup()
 			// check it's valid discrete type
-			if !isDiscreteType(l.input[l.start:l.pos]) &&
-				!isCompositeType(l.input[l.start:l.pos]) {
-				return l.errorf("invalid media type")
+			if mt := string(l.input[l.start:l.pos]); !isDiscreteType(mt) && !isCompositeType(mt) {
+				return l.errorAt(l.start, ErrInvalidMediaType, "invalid media type")
 			}
-			return lexMediaType
+			return stateMediaType
 		case r == eof:
-			return l.errorf("missing media type slash")
+			return l.truncatedf("missing media type slash")
 		case isTokenRune(r):
 		default:
-			return l.errorf("invalid character for media type")
+			return l.errorf(ErrInvalidMediaType, "invalid character for media type")
 		}
 	}
 }
 
-func lexMediaType(l *lexer) stateFn {
+func lexMediaType[T input](l *lexer[T]) lexState {
 	if l.pos > l.start {
 		l.emit(itemMediaType)
 	}
-	return lexMediaSep
+	return stateMediaSep
 }
 
-func lexMediaSep(l *lexer) stateFn {
+func lexMediaSep[T input](l *lexer[T]) lexState {
 	l.next()
 	l.emit(itemMediaSep)
-	return lexAfterMediaSep
+	return stateAfterMediaSep
 }
 
-func lexAfterMediaSep(l *lexer) stateFn {
+func lexAfterMediaSep[T input](l *lexer[T]) lexState {
 	for {
 		switch r := l.next(); {
 		case r == paramSemicolon || r == dataComma:
 			l.backup()
-			return lexMediaSubType
+			return stateMediaSubType
 		case r == eof:
-			return l.errorf("incomplete media type")
+			return l.truncatedf("incomplete media type")
 		case isTokenRune(r):
 		default:
-			return l.errorf("invalid character for media subtype")
+			return l.errorf(ErrInvalidMediaType, "invalid character for media subtype")
 		}
 	}
 }
 
-func lexMediaSubType(l *lexer) stateFn {
+func lexMediaSubType[T input](l *lexer[T]) lexState {
 	if l.pos > l.start {
 		l.emit(itemMediaSubType)
 	}
-	return lexAfterMediaSubType
+	return stateAfterMediaSubType
 }
 
-func lexAfterMediaSubType(l *lexer) stateFn {
+func lexAfterMediaSubType[T input](l *lexer[T]) lexState {
 	switch r := l.next(); r {
 	case paramSemicolon:
 		l.backup()
-		return lexParamSemicolon
+		return stateParamSemicolon
 	case dataComma:
 		l.backup()
-		return lexDataComma
+		return stateDataComma
 	case eof:
-		return l.errorf("missing comma before data")
+		return l.truncatedf("missing comma before data")
 	default:
-		return l.errorf("expected semicolon or comma")
+		return l.errorf(ErrInvalidMediaType, "expected semicolon or comma")
 	}
 }
 
-func lexParamSemicolon(l *lexer) stateFn {
+func lexParamSemicolon[T input](l *lexer[T]) lexState {
 	l.next()
 	l.emit(itemParamSemicolon)
-	return lexAfterParamSemicolon
+	return stateAfterParamSemicolon
 }
 
-func lexAfterParamSemicolon(l *lexer) stateFn {
+func lexAfterParamSemicolon[T input](l *lexer[T]) lexState {
 	switch r := l.next(); {
 	case r == eof:
-		return l.errorf("unterminated parameter sequence")
+		return l.truncatedf("unterminated parameter sequence")
 	case r == paramEqual || r == dataComma:
-		return l.errorf("unterminated parameter sequence")
+		return l.errorf(ErrInvalidParam, "unterminated parameter sequence")
 	case isTokenRune(r):
 		l.backup()
-		return lexInParamAttr
+		return stateInParamAttr
 	default:
-		return l.errorf("invalid character for parameter attribute")
+		return l.errorf(ErrInvalidParam, "invalid character for parameter attribute")
 	}
 }
 
-func lexBase64Enc(l *lexer) stateFn {
+// isBase64Token reports whether s is the base64 token,
+// which is case-insensitive like media types.
+func isBase64Token[T input](s T) bool {
+	return strings.EqualFold(string(s), EncodingBase64)
+}
+
+// lex a parameter without value, before the data comma.
+// It is either the base64 encoding or a flag.
+func lexBase64Enc[T input](l *lexer[T]) lexState {
 	if l.pos > l.start {
-		if v := l.input[l.start:l.pos]; v != "base64" {
-			return l.errorf("expected base64, got %s", v)
+		if !isBase64Token(l.input[l.start:l.pos]) {
+			l.emit(itemParamFlag)
+			return stateDataComma
 		}
-		l.seenBase64Item = true
 		l.emit(itemBase64Enc)
 	}
-	return lexDataComma
+	return stateDataComma
 }
 
-func lexInParamAttr(l *lexer) stateFn {
+// lex a parameter without value, followed by other parameters.
+func lexParamFlag[T input](l *lexer[T]) lexState {
+	if isBase64Token(l.input[l.start:l.pos]) {
+		return l.errorAt(l.pos, ErrInvalidParam, "expected comma after base64")
+	}
+	l.emit(itemParamFlag)
+	return stateParamSemicolon
+}
+
+func lexInParamAttr[T input](l *lexer[T]) lexState {
 	for {
 		switch r := l.next(); {
 		case r == paramEqual:
 			l.backup()
-			return lexParamAttr
+			return stateParamAttr
+		case r == paramSemicolon:
+			l.backup()
+			return stateParamFlag
 		case r == dataComma:
 			l.backup()
-			return lexBase64Enc
+			return stateBase64Enc
 		case r == eof:
-			return l.errorf("unterminated parameter sequence")
+			return l.truncatedf("unterminated parameter sequence")
 		case isTokenRune(r):
 		default:
-			return l.errorf("invalid character for parameter attribute")
+			return l.errorf(ErrInvalidParam, "invalid character for parameter attribute")
 		}
 	}
 }
 
-func lexParamAttr(l *lexer) stateFn {
+func lexParamAttr[T input](l *lexer[T]) lexState {
 	if l.pos > l.start {
 		l.emit(itemParamAttr)
 	}
-	return lexParamEqual
+	return stateParamEqual
 }
 
-func lexParamEqual(l *lexer) stateFn {
+func lexParamEqual[T input](l *lexer[T]) lexState {
 	l.next()
 	l.emit(itemParamEqual)
-	return lexAfterParamEqual
+	return stateAfterParamEqual
 }
 
-func lexAfterParamEqual(l *lexer) stateFn {
+func lexAfterParamEqual[T input](l *lexer[T]) lexState {
 	switch r := l.next(); {
 	case r == '"':
 		l.emit(itemLeftStringQuote)
-		return lexInQuotedStringParamVal
+		return stateInQuotedStringParamVal
 	case r == eof:
-		return l.errorf("missing comma before data")
+		return l.truncatedf("missing comma before data")
 	case isTokenRune(r):
-		return lexInParamVal
+		return stateInParamVal
 	default:
-		return l.errorf("invalid character for parameter value")
+		return l.errorf(ErrInvalidParam, "invalid character for parameter value")
 	}
 }
 
-func lexInQuotedStringParamVal(l *lexer) stateFn {
+func lexInQuotedStringParamVal[T input](l *lexer[T]) lexState {
 	for {
 		switch r := l.next(); {
 		case r == eof:
-			return l.errorf("unclosed quoted string")
+			return l.truncatedf("unclosed quoted string")
 		case r == '\\':
-			return lexEscapedChar
+			return stateEscapedChar
 		case r == '"':
 			l.backup()
-			return lexQuotedStringParamVal
+			return stateQuotedStringParamVal
 		case r <= unicode.MaxASCII:
 		default:
-			return l.errorf("invalid character for parameter value")
+			return l.errorf(ErrInvalidParam, "invalid character for parameter value")
 		}
 	}
 }
 
-func lexEscapedChar(l *lexer) stateFn {
+func lexEscapedChar[T input](l *lexer[T]) lexState {
 	switch r := l.next(); {
 	case r <= unicode.MaxASCII:
-		return lexInQuotedStringParamVal
+		return stateInQuotedStringParamVal
 	case r == eof:
-		return l.errorf("unexpected eof")
+		return l.truncatedf("unexpected eof")
 	default:
-		return l.errorf("invalid escaped character")
+		return l.errorf(ErrInvalidParam, "invalid escaped character")
 	}
 }
 
-func lexInParamVal(l *lexer) stateFn {
+func lexInParamVal[T input](l *lexer[T]) lexState {
 	for {
 		switch r := l.next(); {
 		case r == paramSemicolon || r == dataComma:
 			l.backup()
-			return lexParamVal
+			return stateParamVal
 		case r == eof:
-			return l.errorf("missing comma before data")
+			return l.truncatedf("missing comma before data")
 		case isTokenRune(r):
 		default:
-			return l.errorf("invalid character for parameter value")
+			return l.errorf(ErrInvalidParam, "invalid character for parameter value")
 		}
 	}
 }
 
-func lexQuotedStringParamVal(l *lexer) stateFn {
+func lexQuotedStringParamVal[T input](l *lexer[T]) lexState {
 	if l.pos > l.start {
 		l.emit(itemParamVal)
 	}
 	l.next()
 	l.emit(itemRightStringQuote)
-	return lexAfterParamVal
+	return stateAfterParamVal
 }
 
-func lexParamVal(l *lexer) stateFn {
+func lexParamVal[T input](l *lexer[T]) lexState {
 	if l.pos > l.start {
 		l.emit(itemParamVal)
 	}
-	return lexAfterParamVal
+	return stateAfterParamVal
 }
 
-func lexAfterParamVal(l *lexer) stateFn {
+func lexAfterParamVal[T input](l *lexer[T]) lexState {
 	switch r := l.next(); r {
 	case paramSemicolon:
 		l.backup()
-		return lexParamSemicolon
+		return stateParamSemicolon
 	case dataComma:
 		l.backup()
-		return lexDataComma
+		return stateDataComma
 	case eof:
-		return l.errorf("missing comma before data")
+		return l.truncatedf("missing comma before data")
 	default:
-		return l.errorf("expected semicolon or comma")
+		return l.errorf(ErrInvalidParam, "expected semicolon or comma")
 	}
 }
 
-func lexDataComma(l *lexer) stateFn {
+func lexDataComma[T input](l *lexer[T]) lexState {
 	l.next()
 	l.emit(itemDataComma)
-	if l.seenBase64Item {
-		return lexBase64Data
-	}
-	return lexData
+	return stateData
 }
 
-func lexData(l *lexer) stateFn {
-Loop:
-	for {
-		switch r := l.next(); {
-		case r == eof:
-			break Loop
-		case isURLCharRune(r):
-		default:
-			return l.errorf("invalid data character")
-		}
-	}
-	if l.pos > l.start {
-		l.emit(itemData)
-	}
-	l.emit(itemEOF)
-	return nil
-}
-
-func lexBase64Data(l *lexer) stateFn {
-Loop:
-	for {
-		switch r := l.next(); {
-		case r == eof:
-			break Loop
-		case isBase64Rune(r):
-		default:
-			return l.errorf("invalid data character")
-		}
-	}
+// lex the payload, which is the rest of the input.
+// It is validated by the parser while being decoded,
+// instead of being scanned here.
+func lexData[T input](l *lexer[T]) lexState {
+	l.pos = len(l.input)
 	if l.pos > l.start {
 		l.emit(itemData)
 	}
 	l.emit(itemEOF)
-	return nil
+	return stateEnd
 }
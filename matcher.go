@@ -0,0 +1,147 @@
+package datauri
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Matcher matches media types against a list of patterns compiled
+// once by CompileMatcher, to be reused across validations.
+// A Matcher is safe for concurrent use.
+type Matcher struct {
+	patterns []string
+	exact    map[string][]map[string]string // by "type/subtype"
+	wildcard map[string][]map[string]string // by type, "*" for any
+	suffix   map[string][]map[string]string // by suffix
+}
+
+// CompileMatcher compiles patterns into a Matcher. A pattern is either
+// an exact "type/subtype" media type, a "type/*" or "*/*" wildcard
+// or a "+suffix" structured syntax suffix, as for RegisterDecoder.
+// Types are matched case-insensitively.
+//
+// A pattern may be followed by parameters, e.g. "text/plain;charset=utf-8",
+// which a media type must all have to match. Their attributes and
+// the charset values are compared case-insensitively, the other values
+// exactly. As in HTTP Accept headers, a q parameter is a weight rather
+// than a parameter, and is ignored.
+func CompileMatcher(patterns ...string) (*Matcher, error) {
+	m := &Matcher{
+		patterns: patterns,
+		exact:    make(map[string][]map[string]string),
+		wildcard: make(map[string][]map[string]string),
+		suffix:   make(map[string][]map[string]string),
+	}
+	for _, pattern := range patterns {
+		base, params, err := parsePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("datauri: invalid media type pattern %q: %w", pattern, err)
+		}
+		switch t, st, _ := strings.Cut(base, "/"); {
+		case strings.HasPrefix(base, "+"):
+			m.suffix[base[1:]] = append(m.suffix[base[1:]], params)
+		case st == "*":
+			m.wildcard[t] = append(m.wildcard[t], params)
+		case t == "*" || st == "":
+			return nil, fmt.Errorf("datauri: invalid media type pattern %q", pattern)
+		default:
+			m.exact[base] = append(m.exact[base], params)
+		}
+	}
+	return m, nil
+}
+
+// parsePattern returns the lowercased media type of pattern,
+// and its parameters, without the q weight.
+func parsePattern(pattern string) (string, map[string]string, error) {
+	var (
+		base   string
+		params map[string]string
+		err    error
+	)
+	if strings.HasPrefix(pattern, "+") {
+		// not a media type, parse the parameters only
+		var rest string
+		base, rest, _ = strings.Cut(pattern, ";")
+		base = strings.ToLower(strings.TrimSpace(base))
+		_, params, err = mime.ParseMediaType("a/b;" + rest)
+	} else {
+		base, params, err = mime.ParseMediaType(pattern)
+	}
+	delete(params, "q")
+	return base, params, err
+}
+
+// Match reports whether mt matches one of the patterns of m.
+func (m *Matcher) Match(mt *MediaType) bool {
+	t, st := strings.ToLower(mt.Type), strings.ToLower(mt.Subtype)
+	if matchParams(m.exact[t+"/"+st], mt) ||
+		matchParams(m.wildcard[t], mt) ||
+		matchParams(m.wildcard["*"], mt) {
+		return true
+	}
+	if _, suffix := splitSuffix(st); suffix != "" {
+		return matchParams(m.suffix[suffix], mt)
+	}
+	return false
+}
+
+// MatchString is like Match, for a media type string
+// like "text/plain;charset=utf-8".
+func (m *Matcher) MatchString(s string) bool {
+	base, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return false
+	}
+	t, st, _ := strings.Cut(base, "/")
+	return m.Match(&MediaType{Type: t, Subtype: st, Params: params})
+}
+
+// matchParams reports whether mt has all the parameters
+// of one of alternatives.
+func matchParams(alternatives []map[string]string, mt *MediaType) bool {
+	for _, params := range alternatives {
+		if hasParams(mt, params) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasParams(mt *MediaType, params map[string]string) bool {
+	for attr, want := range params {
+		got, ok := lookupParam(mt, attr)
+		if !ok {
+			return false
+		}
+		if attr == "charset" && !strings.EqualFold(got, want) || attr != "charset" && got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether mt matches pattern, a media type,
+// a "type/*" or "*/*" wildcard, or a "+suffix", with optional
+// parameters, as used in HTTP Accept headers, e.g. "image/*" or
+// "text/plain;charset=utf-8", see CompileMatcher. It is false
+// for an invalid pattern.
+func (mt *MediaType) Matches(pattern string) bool {
+	m, err := CompileMatcher(pattern)
+	return err == nil && m.Match(mt)
+}
+
+// lookupParam returns the value of the parameter attr of mt,
+// attr being lowercase.
+func lookupParam(mt *MediaType, attr string) (string, bool) {
+	if v, ok := mt.Params[attr]; ok {
+		return v, true
+	}
+	for k, v := range mt.Params {
+		if strings.EqualFold(k, attr) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,116 @@
+package datauri
+
+import "testing"
+
+func TestMatcher(t *testing.T) {
+	m, err := CompileMatcher(
+		"image/png",
+		"text/*",
+		"+json",
+		"application/xml;charset=utf-8",
+		"+cbor;version=2",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		MediaType string
+		Expected  bool
+	}{
+		{"image/png", true},
+		{"IMAGE/PNG", true},
+		{"image/jpeg", false},
+		{"text/plain;charset=utf-8", true},
+		{"text/csv", true},
+		{"application/json", false},
+		{"application/vnd.api+json", true},
+		{"application/xml", false},
+		{"application/xml;charset=UTF-8", true},
+		{"application/xml;Charset=utf-8;foo=bar", true},
+		{"application/xml;charset=latin1", false},
+		{"application/vnd.foo+cbor", false},
+		{"application/vnd.foo+cbor;version=2", true},
+		{"not a media type", false},
+	}
+	for _, test := range tests {
+		if got := m.MatchString(test.MediaType); got != test.Expected {
+			t.Errorf("%s: expected %v, got %v", test.MediaType, test.Expected, got)
+		}
+	}
+
+	du := New([]byte("heya"), "image/png")
+	if !m.Match(&du.MediaType) {
+		t.Errorf("Expected %s to match", du.ContentType())
+	}
+}
+
+func TestMatcherAny(t *testing.T) {
+	m, err := CompileMatcher("*/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.MatchString("application/octet-stream") {
+		t.Error("Expected */* to match application/octet-stream")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	png := MediaType{Type: "image", Subtype: "png"}
+	utf8 := MediaType{Type: "text", Subtype: "plain", Params: map[string]string{"charset": "utf-8"}}
+	tests := []struct {
+		MediaType MediaType
+		Pattern   string
+		Expected  bool
+	}{
+		{png, "image/png", true},
+		{png, "Image/PNG", true},
+		{png, "image/*", true},
+		{png, "*/*", true},
+		{png, "*/*;q=0.8", true},
+		{png, "image/jpeg", false},
+		{png, "text/*", false},
+		{png, "image/png;foo=bar", false},
+		{png, "image", false},
+		{utf8, "text/plain;charset=UTF-8", true},
+		{utf8, "text/*;charset=utf-8;q=0.5", true},
+		{utf8, "text/plain;charset=us-ascii", false},
+	}
+	for _, test := range tests {
+		if got := test.MediaType.Matches(test.Pattern); got != test.Expected {
+			t.Errorf("%s, %s: expected %v, got %v", test.MediaType.String(), test.Pattern, test.Expected, got)
+		}
+	}
+}
+
+func TestCompileMatcherErrors(t *testing.T) {
+	for _, pattern := range []string{"", "image", "*/png", "text/plain;charset"} {
+		if _, err := CompileMatcher(pattern); err == nil {
+			t.Errorf("%q: expected error", pattern)
+		}
+	}
+}
+
+func TestPolicyMediaTypes(t *testing.T) {
+	p := Policy{AllowedMediaTypes: []string{"image/*", "+json", "text/plain;charset=utf-8"}}
+	for _, du := range []*DataURI{
+		New([]byte("{}"), "application/vnd.api+json"),
+		New([]byte("heya"), "text/plain", "charset", "UTF-8"),
+	} {
+		if err := p.Check(du); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+	expected := "datauri: media_type_not_allowed: mediatype is text/plain, limit is [image/* +json text/plain;charset=utf-8]"
+	if err := p.Check(New([]byte("heya"), "text/plain")); err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	p = Policy{AllowedMediaTypes: []string{"*/*"}}
+	if err := p.Check(New([]byte("heya"), "font/woff2")); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	p = Policy{AllowedMediaTypes: []string{"image"}}
+	if err := p.Check(New([]byte("heya"), "image/png")); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+}
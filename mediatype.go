@@ -0,0 +1,170 @@
+package datauri
+
+import (
+	"strings"
+)
+
+// defaultExtensionPrefixes are the prefixes of the attributes of
+// extension parameters, when none are given.
+var defaultExtensionPrefixes = []string{"x-", "vnd."}
+
+// isExtensionParam reports whether attr starts with one of prefixes,
+// case-insensitively.
+func isExtensionParam(attr string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		prefixes = defaultExtensionPrefixes
+	}
+	for _, prefix := range prefixes {
+		if len(attr) >= len(prefix) && strings.EqualFold(attr[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtensionParams returns the namespaced parameters of mt,
+// whose attributes start with one of prefixes, case-insensitively.
+// Without prefixes, the "x-" and "vnd." prefixes are used.
+func (mt *MediaType) ExtensionParams(prefixes ...string) map[string]string {
+	params := make(map[string]string)
+	for k, v := range mt.Params {
+		if isExtensionParam(k, prefixes) {
+			params[k] = v
+		}
+	}
+	return params
+}
+
+// FilterParams removes the parameters of mt for which keep returns false.
+func (mt *MediaType) FilterParams(keep func(attribute, value string) bool) {
+	for k, v := range mt.Params {
+		if !keep(k, v) {
+			delete(mt.Params, k)
+		}
+	}
+}
+
+// StripExtensions removes the namespaced parameters of mt,
+// as returned by ExtensionParams.
+func (mt *MediaType) StripExtensions(prefixes ...string) {
+	mt.FilterParams(func(attribute, _ string) bool {
+		return !isExtensionParam(attribute, prefixes)
+	})
+}
+
+// splitSuffix splits subtype into its base and its structured
+// syntax suffix (RFC 6838), e.g. "svg+xml" into "svg" and "xml".
+func splitSuffix(subtype string) (base, suffix string) {
+	if i := strings.LastIndexByte(subtype, '+'); i >= 0 {
+		return subtype[:i], subtype[i+1:]
+	}
+	return subtype, ""
+}
+
+// Suffix returns the structured syntax suffix (RFC 6838) of the subtype
+// of mt, e.g. "xml" for image/svg+xml and "json" for
+// application/vnd.acme+json, or an empty string if it has none.
+func (mt *MediaType) Suffix() string {
+	_, suffix := splitSuffix(mt.Subtype)
+	return suffix
+}
+
+// BaseSubtype returns the subtype of mt without its structured syntax
+// suffix, e.g. "svg" for image/svg+xml, see Suffix.
+func (mt *MediaType) BaseSubtype() string {
+	base, _ := splitSuffix(mt.Subtype)
+	return base
+}
+
+// Registration trees of media subtypes (RFC 6838), returned by Tree.
+const (
+	TreeStandards    = "standards"
+	TreeVendor       = "vnd"
+	TreePersonal     = "prs"
+	TreeUnregistered = "x"
+)
+
+// Base returns a copy of mt without parameters.
+func (mt *MediaType) Base() MediaType {
+	return MediaType{
+		Type:    mt.Type,
+		Subtype: mt.Subtype,
+		Params:  make(map[string]string),
+	}
+}
+
+// WithoutParams returns a copy of mt without the parameters
+// of attributes, compared case-insensitively.
+func (mt *MediaType) WithoutParams(attributes ...string) MediaType {
+	cp := mt.Base()
+	for k, v := range mt.Params {
+		cp.Params[k] = v
+	}
+	cp.FilterParams(func(attribute, _ string) bool {
+		for _, attr := range attributes {
+			if strings.EqualFold(attr, attribute) {
+				return false
+			}
+		}
+		return true
+	})
+	return cp
+}
+
+// WithSuffix returns a copy of mt whose subtype has the structured syntax
+// suffix suffix, e.g. "vnd.api+json" for "vnd.api" and "json".
+// An existing suffix is replaced, an empty suffix removes it.
+func (mt *MediaType) WithSuffix(suffix string) MediaType {
+	cp := mt.WithoutParams()
+	cp.Subtype = mt.BaseSubtype()
+	if suffix != "" {
+		cp.Subtype += "+" + suffix
+	}
+	return cp
+}
+
+// Tree returns the registration tree of the subtype of mt:
+// TreeVendor for "vnd.", TreePersonal for "prs.", TreeUnregistered
+// for "x." and "x-", and TreeStandards otherwise.
+func (mt *MediaType) Tree() string {
+	st := strings.ToLower(mt.Subtype)
+	switch {
+	case strings.HasPrefix(st, "vnd."):
+		return TreeVendor
+	case strings.HasPrefix(st, "prs."):
+		return TreePersonal
+	case strings.HasPrefix(st, "x.") || strings.HasPrefix(st, "x-"):
+		return TreeUnregistered
+	}
+	return TreeStandards
+}
+
+// Vendor returns the producer of a subtype of the vendor tree,
+// e.g. "ms-excel" for "vnd.ms-excel" and "openxmlformats-officedocument"
+// for "vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+// or an empty string for the other trees.
+func (mt *MediaType) Vendor() string {
+	if mt.Tree() != TreeVendor {
+		return ""
+	}
+	vendor, _, _ := strings.Cut(mt.BaseSubtype()[len("vnd."):], ".")
+	return vendor
+}
+
+// Charset returns the charset of mt, following the fallback rules
+// of RFC 2046 and RFC 2397:
+//   - the value of the charset parameter, which is explicit,
+//   - US-ASCII for text media types, and when there is no media type,
+//   - none for the other, binary, media types.
+//
+// Note that DecodeString sets the charset parameter of the default
+// text/plain;charset=US-ASCII media type of Data URIs without one.
+func (mt *MediaType) Charset() (name string, explicit bool) {
+	if charset, ok := lookupParam(mt, "charset"); ok && charset != "" {
+		return charset, true
+	}
+	if mt.Type == "" || strings.EqualFold(mt.Type, "text") {
+		return "US-ASCII", false
+	}
+	return "", false
+}
@@ -0,0 +1,154 @@
+package datauri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtensionParams(t *testing.T) {
+	tests := []struct {
+		Params   map[string]string
+		Prefixes []string
+		Expected map[string]string
+	}{
+		{
+			map[string]string{"charset": "utf-8", "x-foo": "bar", "X-Bar": "baz", "vnd.acme.id": "1"},
+			nil,
+			map[string]string{"x-foo": "bar", "X-Bar": "baz", "vnd.acme.id": "1"},
+		},
+		{
+			map[string]string{"charset": "utf-8", "x-foo": "bar", "acme-id": "1"},
+			[]string{"acme-"},
+			map[string]string{"acme-id": "1"},
+		},
+		{
+			map[string]string{"charset": "utf-8"},
+			nil,
+			map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		mt := MediaType{Type: "text", Subtype: "plain", Params: test.Params}
+		if got := mt.ExtensionParams(test.Prefixes...); !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("Expected %v, got %v", test.Expected, got)
+		}
+	}
+}
+
+func TestStripExtensions(t *testing.T) {
+	du := New([]byte("heya"), "text/plain", "charset", "utf-8", "x-foo", "bar", "vnd.acme.id", "1")
+	du.StripExtensions()
+	expected := "data:text/plain;charset=utf-8;base64,aGV5YQ=="
+	if got := du.String(); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	du = New([]byte("heya"), "text/plain", "charset", "utf-8", "acme-id", "1", "x-foo", "bar")
+	du.StripExtensions("ACME-")
+	expected = "data:text/plain;charset=utf-8;x-foo=bar;base64,aGV5YQ=="
+	if got := du.String(); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestFilterParams(t *testing.T) {
+	mt := MediaType{Type: "text", Subtype: "plain", Params: map[string]string{"charset": "utf-8", "name": "", "foo": "bar"}}
+	mt.FilterParams(func(_, value string) bool {
+		return value != ""
+	})
+	expected := map[string]string{"charset": "utf-8", "foo": "bar"}
+	if !reflect.DeepEqual(mt.Params, expected) {
+		t.Errorf("Expected %v, got %v", expected, mt.Params)
+	}
+}
+
+func TestMediaTypeArithmetic(t *testing.T) {
+	mt := MediaType{Type: "application", Subtype: "vnd.api+json", Params: map[string]string{"charset": "utf-8", "ext": "bulk"}}
+
+	if got := mt.Base(); got.String() != "application/vnd.api+json" {
+		t.Errorf("Expected %s, got %s", "application/vnd.api+json", got.String())
+	}
+	if got := mt.WithoutParams("EXT"); got.String() != "application/vnd.api+json;charset=utf-8" {
+		t.Errorf("Expected %s, got %s", "application/vnd.api+json;charset=utf-8", got.String())
+	}
+	if got := mt.WithSuffix("cbor"); got.String() != "application/vnd.api+cbor;charset=utf-8;ext=bulk" {
+		t.Errorf("Expected %s, got %s", "application/vnd.api+cbor;charset=utf-8;ext=bulk", got.String())
+	}
+	if got := mt.WithSuffix(""); got.ContentType() != "application/vnd.api" {
+		t.Errorf("Expected %s, got %s", "application/vnd.api", got.ContentType())
+	}
+	if len(mt.Params) != 2 || mt.Subtype != "vnd.api+json" {
+		t.Errorf("Expected mt to be left unmodified, got %s", mt.String())
+	}
+}
+
+func TestTree(t *testing.T) {
+	tests := []struct {
+		Subtype        string
+		ExpectedTree   string
+		ExpectedVendor string
+	}{
+		{"json", TreeStandards, ""},
+		{"svg+xml", TreeStandards, ""},
+		{"vnd.ms-excel", TreeVendor, "ms-excel"},
+		{"VND.openxmlformats-officedocument.spreadsheetml.sheet", TreeVendor, "openxmlformats-officedocument"},
+		{"vnd.api+json", TreeVendor, "api"},
+		{"prs.btf", TreePersonal, ""},
+		{"x-www-form-urlencoded", TreeUnregistered, ""},
+		{"x.foo", TreeUnregistered, ""},
+	}
+	for _, test := range tests {
+		mt := MediaType{Type: "application", Subtype: test.Subtype}
+		if got := mt.Tree(); got != test.ExpectedTree {
+			t.Errorf("%s: expected %s, got %s", test.Subtype, test.ExpectedTree, got)
+		}
+		if got := mt.Vendor(); got != test.ExpectedVendor {
+			t.Errorf("%s: expected %s, got %s", test.Subtype, test.ExpectedVendor, got)
+		}
+	}
+}
+
+func TestSuffix(t *testing.T) {
+	tests := []struct {
+		Subtype             string
+		ExpectedSuffix      string
+		ExpectedBaseSubtype string
+	}{
+		{"json", "", "json"},
+		{"svg+xml", "xml", "svg"},
+		{"vnd.acme+json", "json", "vnd.acme"},
+		{"vnd.a+b+zip", "zip", "vnd.a+b"},
+		{"", "", ""},
+	}
+	for _, test := range tests {
+		mt := MediaType{Type: "application", Subtype: test.Subtype}
+		if got := mt.Suffix(); got != test.ExpectedSuffix {
+			t.Errorf("%s: expected %s, got %s", test.Subtype, test.ExpectedSuffix, got)
+		}
+		if got := mt.BaseSubtype(); got != test.ExpectedBaseSubtype {
+			t.Errorf("%s: expected %s, got %s", test.Subtype, test.ExpectedBaseSubtype, got)
+		}
+	}
+}
+
+func TestCharset(t *testing.T) {
+	tests := []struct {
+		MediaType        MediaType
+		ExpectedName     string
+		ExpectedExplicit bool
+	}{
+		{MediaType{Type: "text", Subtype: "plain", Params: map[string]string{"charset": "utf-8"}}, "utf-8", true},
+		{MediaType{Type: "text", Subtype: "html", Params: map[string]string{"Charset": "latin1"}}, "latin1", true},
+		{MediaType{Type: "text", Subtype: "csv"}, "US-ASCII", false},
+		{MediaType{}, "US-ASCII", false},
+		{MediaType{Type: "image", Subtype: "png"}, "", false},
+		{MediaType{Type: "application", Subtype: "json", Params: map[string]string{"charset": "utf-8"}}, "utf-8", true},
+	}
+	for _, test := range tests {
+		name, explicit := test.MediaType.Charset()
+		if name != test.ExpectedName || explicit != test.ExpectedExplicit {
+			t.Errorf("%s: expected %s, %v, got %s, %v", test.MediaType.String(),
+				test.ExpectedName, test.ExpectedExplicit, name, explicit)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package datauri
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+	// pngMetadataChunks are the PNG chunks holding metadata
+	// rather than image data.
+	pngMetadataChunks = map[string]bool{
+		"eXIf": true,
+		"tEXt": true,
+		"zTXt": true,
+		"iTXt": true,
+		"tIME": true,
+	}
+)
+
+// StripMetadata returns a Rule removing the metadata of JPEG, PNG and
+// WebP payloads, such as the EXIF data of photos, which may hold their
+// GPS position or the serial number of the camera, see WithoutMetadata.
+func StripMetadata() Rule {
+	return func(du *DataURI) error {
+		du.Data = stripMetadata(du.Data)
+		return nil
+	}
+}
+
+// WithoutMetadata writes the payload without the metadata of JPEG, PNG
+// and WebP images, whatever the media type, the format being detected
+// from the payload:
+//   - the EXIF, XMP and IPTC segments, and the comments of JPEG images,
+//   - the eXIf, text and tIME chunks of PNG images,
+//   - the EXIF and XMP chunks of WebP images.
+//
+// The color profiles are kept. Malformed images are written as is.
+func WithoutMetadata() EncodeOption {
+	return func(o *encodeOptions) {
+		o.stripMetadata = true
+	}
+}
+
+// stripMetadata returns data without its image metadata,
+// or data itself if it has none, or is not a known image format.
+func stripMetadata(data []byte) []byte {
+	var (
+		stripped []byte
+		ok       bool
+	)
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8}):
+		stripped, ok = stripJPEGMetadata(data)
+	case bytes.HasPrefix(data, pngSignature):
+		stripped, ok = stripPNGMetadata(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		stripped, ok = stripWebPMetadata(data)
+	}
+	if !ok {
+		return data
+	}
+	return stripped
+}
+
+// stripJPEGMetadata removes the APP1 (EXIF, XMP), APP13 (IPTC) and COM
+// segments of the JPEG image data, up to the start of the scan.
+func stripJPEGMetadata(data []byte) ([]byte, bool) {
+	out := append([]byte(nil), data[:2]...)
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xff {
+			return nil, false
+		}
+		marker := data[i+1]
+		if marker == 0xda {
+			// start of scan, the entropy-coded data follows
+			return append(out, data[i:]...), true
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return nil, false
+		}
+		switch marker {
+		case 0xe1, 0xed, 0xfe:
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+}
+
+// stripPNGMetadata removes the chunks of pngMetadataChunks
+// from the PNG image data.
+func stripPNGMetadata(data []byte) ([]byte, bool) {
+	out := append([]byte(nil), pngSignature...)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, false
+		}
+		// length, type, data and CRC
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, false
+		}
+		if !pngMetadataChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, true
+}
+
+// stripWebPMetadata removes the EXIF and XMP chunks of the WebP image
+// data, with their flags in the VP8X chunk.
+func stripWebPMetadata(data []byte) ([]byte, bool) {
+	out := append([]byte(nil), data[:12]...)
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, false
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size&1 // chunks are padded to an even size
+		if end > len(data) || end < i {
+			return nil, false
+		}
+		switch fourCC := string(data[i : i+4]); fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				// the EXIF and XMP flags
+				out[start+8] &^= 0x08 | 0x04
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, true
+}
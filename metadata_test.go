@@ -0,0 +1,109 @@
+package datauri
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func jpegSegment(marker byte, payload string) []byte {
+	b := []byte{0xff, marker, 0, 0}
+	binary.BigEndian.PutUint16(b[2:], uint16(len(payload)+2))
+	return append(b, payload...)
+}
+
+func pngChunk(typ, payload string) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	b = append(b, typ+payload...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE([]byte(typ+payload)))
+}
+
+func webpChunk(fourCC, payload string) []byte {
+	b := append([]byte(fourCC), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(payload)))
+	b = append(b, payload...)
+	if len(payload)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func webp(chunks ...[]byte) []byte {
+	b := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, c := range chunks {
+		b = append(b, c...)
+	}
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)-8))
+	return b
+}
+
+func TestStripMetadata(t *testing.T) {
+	join := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 3))); err != nil {
+		t.Fatal(err)
+	}
+	pngData := buf.Bytes()
+	ihdrEnd := len(pngSignature) + 25 // IHDR chunk of 13 bytes
+
+	sos := []byte{0xff, 0xda, 0, 2, 1, 2, 3, 0xff, 0xd9}
+	tests := []struct {
+		Name     string
+		Input    []byte
+		Expected []byte
+	}{
+		{
+			"jpeg",
+			join([]byte{0xff, 0xd8}, jpegSegment(0xe0, "JFIF\x00"), jpegSegment(0xe1, "Exif\x00\x00GPS"),
+				jpegSegment(0xfe, "comment"), jpegSegment(0xe2, "ICC_PROFILE"), sos),
+			join([]byte{0xff, 0xd8}, jpegSegment(0xe0, "JFIF\x00"), jpegSegment(0xe2, "ICC_PROFILE"), sos),
+		},
+		{
+			"png",
+			join(pngData[:ihdrEnd], pngChunk("eXIf", "MM\x00*GPS"), pngChunk("tEXt", "Author\x00me"), pngData[ihdrEnd:]),
+			pngData,
+		},
+		{
+			"webp",
+			webp(webpChunk("VP8X", "\x0c\x00\x00\x00\x01\x00\x00\x02\x00\x00"), webpChunk("VP8 ", "frame"),
+				webpChunk("EXIF", "MM\x00*GPS"), webpChunk("XMP ", "<x/>")),
+			webp(webpChunk("VP8X", "\x00\x00\x00\x00\x01\x00\x00\x02\x00\x00"), webpChunk("VP8 ", "frame")),
+		},
+		{
+			"truncated jpeg",
+			join([]byte{0xff, 0xd8}, jpegSegment(0xe1, "Exif")[:5]),
+			join([]byte{0xff, 0xd8}, jpegSegment(0xe1, "Exif")[:5]),
+		},
+		{"text", []byte("Exif"), []byte("Exif")},
+	}
+	for _, test := range tests {
+		du := New(test.Input, "application/octet-stream")
+		if err := Rewrite(StripMetadata()).Apply(du); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(du.Data, test.Expected) {
+			t.Errorf("%s: expected %q, got %q", test.Name, test.Expected, du.Data)
+		}
+		expected := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(test.Expected)
+		if got := New(test.Input, "application/octet-stream").StringWith(WithoutMetadata()); got != expected {
+			t.Errorf("%s: expected %s, got %s", test.Name, expected, got)
+		}
+	}
+
+	stripped := EncodeBytes(tests[1].Input, WithoutMetadata())
+	du, err := DecodeString(stripped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(du.Data)); err != nil {
+		t.Errorf("Expected a valid PNG image, got %v", err)
+	}
+	if !bytes.Equal(du.Data, pngData) {
+		t.Errorf("Expected %q, got %q", pngData, du.Data)
+	}
+}
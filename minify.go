@@ -0,0 +1,33 @@
+package datauri
+
+import "strings"
+
+// Minify returns du written as the shortest Data URI with the same
+// content, e.g. for emails or QR codes: it is written as by Version2,
+// see V, which omits the default text/plain media type and US-ASCII
+// charset, with the shortest of the ASCII and base64 encodings, the
+// latter without padding. The decoders of this package read it back.
+func (du *DataURI) Minify() string {
+	if du.IsZero() {
+		return ""
+	}
+	c := *du
+	c.Encoding = EncodingASCII
+	ascii := c.StringWith(V(Version2))
+	c.Encoding = EncodingBase64
+	b64 := strings.TrimRight(c.StringWith(V(Version2)), "=")
+	if len(b64) < len(ascii) {
+		return b64
+	}
+	return ascii
+}
+
+// MinifyString decodes the Data URI s with opts and returns it
+// minified, see DataURI.Minify.
+func MinifyString(s string, opts ...Option) (string, error) {
+	du, err := DecodeString(s, opts...)
+	if err != nil {
+		return "", err
+	}
+	return du.Minify(), nil
+}
@@ -0,0 +1,44 @@
+package datauri
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		InputString string
+		Expected    string
+	}{
+		{"data:text/plain;charset=US-ASCII;base64,QSBicmllZiBub3Rl", "data:,A%20brief%20note"},
+		{"data:text/plain;charset=utf-8;base64,aGV5YQ==", "data:;charset=utf-8,heya"},
+		{"data:Text/HTML,%3Cp%3E", "data:text/html,%3Cp%3E"},
+		{"data:application/octet-stream,%00%01%FF%FE", "data:application/octet-stream,%00%01%FF%FE"},
+		{"data:application/octet-stream,%FF%FF%FF%FF%FF", "data:application/octet-stream;base64,//////8"},
+		{"data:image/png;base64,iVBORw0KGgoAAAAN", "data:image/png;base64,iVBORw0KGgoAAAAN"},
+		{"data:,", "data:,"},
+	}
+	for _, test := range tests {
+		got, err := MinifyString(test.InputString, WithRoundTrip())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.Expected {
+			t.Errorf("%s: expected %s, got %s", test.InputString, test.Expected, got)
+		}
+		original, _ := DecodeString(test.InputString)
+		minified, err := DecodeString(got)
+		if err != nil {
+			t.Fatalf("%s: %v", got, err)
+		}
+		if !bytes.Equal(minified.Data, original.Data) || !minified.EqualContent(original) {
+			t.Errorf("%s: expected %v, got %v", got, original, minified)
+		}
+	}
+	if _, err := MinifyString("notadatauri"); err == nil {
+		t.Error("Expected an error for an invalid Data URI")
+	}
+	if got := (&DataURI{}).Minify(); got != "" {
+		t.Errorf("Expected an empty string, got %s", got)
+	}
+}
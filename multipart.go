@@ -0,0 +1,224 @@
+package datauri
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+const (
+	multipartRootID   = "root@datauri"
+	multipartLineSize = 76
+)
+
+// WriteMultipartRelated writes the HTML document html to w as a
+// multipart/related body (RFC 2387), as expected by MHTML-style consumers.
+// Every data URI found in html is replaced by a "cid:" reference to
+// a part holding its payload; identical data URIs share the same part.
+//
+// It returns the value of the Content-Type header of the body,
+// which includes the boundary of the parts.
+func WriteMultipartRelated(w io.Writer, html string) (string, error) {
+	var (
+		root  strings.Builder
+		ids   = make(map[string]string)
+		parts []*DataURI
+		last  int
+	)
+	for _, span := range findDataURIs(html) {
+		s := html[span[0]:span[1]]
+		id, ok := ids[s]
+		if !ok {
+			du, err := DecodeString(s)
+			if err != nil {
+				continue
+			}
+			id = fmt.Sprintf("part%d@datauri", len(parts)+1)
+			ids[s] = id
+			parts = append(parts, du)
+		}
+		root.WriteString(html[last:span[0]])
+		root.WriteString("cid:" + id)
+		last = span[1]
+	}
+	root.WriteString(html[last:])
+
+	mw := multipart.NewWriter(w)
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/html; charset=utf-8"},
+		"Content-Id":   {"<" + multipartRootID + ">"},
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(pw, root.String()); err != nil {
+		return "", err
+	}
+	for i, du := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(du.ContentType(), du.Params)},
+			"Content-Id":                {fmt.Sprintf("<part%d@datauri>", i+1)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := writeBase64Lines(pw, du.Data); err != nil {
+			return "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	return mime.FormatMediaType("multipart/related", map[string]string{
+		"boundary": mw.Boundary(),
+		"type":     "text/html",
+		"start":    "<" + multipartRootID + ">",
+	}), nil
+}
+
+// ReadMultipartRelated is the inverse of WriteMultipartRelated.
+// It reads the multipart/related body r, whose Content-Type header value
+// is contentType, and returns its root document where the "cid:" references
+// to the other parts are replaced by data URIs holding their content.
+//
+// The root document is the part designated by the "start" parameter
+// of contentType, or the first part.
+func ReadMultipartRelated(r io.Reader, contentType string) (string, error) {
+	mt, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+	if mt != "multipart/related" {
+		return "", fmt.Errorf("datauri: expected multipart/related, got %s", mt)
+	}
+	if params["boundary"] == "" {
+		return "", errors.New("datauri: missing multipart boundary")
+	}
+
+	var (
+		root    *string
+		dus     = make(map[string]*DataURI)
+		startID = strings.Trim(params["start"], "<>")
+		mr      = multipart.NewReader(r, params["boundary"])
+	)
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		data, err := readPart(p)
+		if err != nil {
+			return "", err
+		}
+		id := strings.Trim(p.Header.Get("Content-Id"), "<>")
+		if root == nil && (startID == "" || id == startID) {
+			s := string(data)
+			root = &s
+			continue
+		}
+		if id == "" {
+			continue
+		}
+		du, err := partDataURI(p.Header.Get("Content-Type"), data)
+		if err != nil {
+			return "", err
+		}
+		dus[id] = du
+	}
+	if root == nil {
+		return "", errors.New("datauri: root part not found")
+	}
+
+	// replace the longest identifiers first, in case
+	// some of them are prefixes of others
+	ids := make([]string, 0, len(dus))
+	for id := range dus {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return len(ids[i]) > len(ids[j])
+	})
+	html := *root
+	for _, id := range ids {
+		html = strings.ReplaceAll(html, "cid:"+id, dus[id].String())
+	}
+	return html, nil
+}
+
+// readPart reads the content of p, decoding its transfer encoding.
+// Quoted-printable parts are decoded by the multipart package.
+func readPart(p *multipart.Part) ([]byte, error) {
+	var r io.Reader = p
+	if strings.EqualFold(p.Header.Get("Content-Transfer-Encoding"), "base64") {
+		r = base64.NewDecoder(base64.StdEncoding, p)
+	}
+	return io.ReadAll(r)
+}
+
+func partDataURI(contentType string, data []byte) (*DataURI, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	mt, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]string, 0, 2*len(params))
+	for k, v := range params {
+		pairs = append(pairs, k, v)
+	}
+	return New(data, mt, pairs...), nil
+}
+
+// writeBase64Lines writes data to w in base64, in lines of
+// multipartLineSize characters, as required by RFC 2045.
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	for len(encoded) > multipartLineSize {
+		buf.WriteString(encoded[:multipartLineSize])
+		buf.WriteString("\r\n")
+		encoded = encoded[multipartLineSize:]
+	}
+	buf.WriteString(encoded)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// findDataURIs returns the start and end offsets of the
+// candidate data URIs found in s.
+func findDataURIs(s string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(s); {
+		j := strings.Index(s[i:], dataPrefix)
+		if j < 0 {
+			break
+		}
+		start := i + j
+		end := start + len(dataPrefix)
+		for end < len(s) && isEmbeddedURLChar(rune(s[end])) {
+			end++
+		}
+		spans = append(spans, [2]int{start, end})
+		i = end
+	}
+	return spans
+}
+
+// isEmbeddedURLChar reports whether r can be part of a data URI
+// embedded in a document, where it is usually delimited by quotes
+// or by parentheses.
+func isEmbeddedURLChar(r rune) bool {
+	return isURLCharRune(r) && r != '\'' && r != ')'
+}
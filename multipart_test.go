@@ -0,0 +1,72 @@
+package datauri
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+	"testing"
+)
+
+func TestMultipartRelated(t *testing.T) {
+	logo := New([]byte("\x89PNG\x0D\x0A\x1A\x0A"), "image/png", "name", "logo")
+	note := New([]byte("A brief note"), "text/plain", "charset", "utf-8")
+	big := New(bytes.Repeat([]byte("0123456789"), 20), "application/octet-stream")
+	html := `<html><body>
+<img src="` + logo.String() + `">
+<div style="background: url(` + logo.String() + `)"></div>
+<a href='` + note.String() + `'>note</a>
+<a href="` + big.String() + `">big</a>
+<a href="data:xxx">invalid</a>
+</body></html>`
+
+	var buf bytes.Buffer
+	contentType, err := WriteMultipartRelated(&buf, html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt != "multipart/related" || params["type"] != "text/html" || params["start"] == "" {
+		t.Errorf("Unexpected content type %s", contentType)
+	}
+	body := buf.String()
+	if strings.Contains(body, logo.String()) || strings.Contains(body, note.String()) {
+		t.Error("Expected data URIs to be replaced")
+	}
+	if n := strings.Count(body, "cid:part1@datauri"); n != 2 {
+		t.Errorf("Expected identical data URIs to share the same part, got %d references", n)
+	}
+	if !strings.Contains(body, `data:xxx`) {
+		t.Error("Expected invalid data URIs to be left untouched")
+	}
+	if strings.Contains(body, strings.TrimPrefix(big.String(), "data:application/octet-stream;base64,")) {
+		t.Error("Expected base64 parts to be wrapped")
+	}
+
+	got, err := ReadMultipartRelated(&buf, contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != html {
+		t.Errorf("Expected %s, got %s", html, got)
+	}
+}
+
+func TestReadMultipartRelatedErrors(t *testing.T) {
+	tests := []struct {
+		Body        string
+		ContentType string
+	}{
+		{"", "multipart/mixed; boundary=foo"},
+		{"", "multipart/related"},
+		{"", "multipart/related; boundary=foo"},
+		{"--foo\r\nContent-Id: <a>\r\n\r\nabc\r\n--foo--\r\n", "multipart/related; boundary=foo; start=\"<b>\""},
+	}
+	for _, test := range tests {
+		if _, err := ReadMultipartRelated(strings.NewReader(test.Body), test.ContentType); err == nil {
+			t.Errorf("%s: expected error", test.ContentType)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package datauri
+
+// Option configures how Data URIs are decoded by DecodeString and Decode.
+type Option func(*options)
+
+type options struct {
+	roundTrip    bool
+	errorBudget  int
+	corrections  *[]Correction
+	rateLimit    int
+	strict       bool
+	alloc        func(n int) []byte
+	maxDataSize  int64
+	mediaTypes   *Matcher
+	noCharset    bool
+	mediaType    *MediaType // the default one
+	charset      CharsetPolicy
+	timing       *Timing
+	repairHeader bool
+	validateOnly bool  // the payload is checked, not decoded
+	err          error // of an invalid option
+}
+
+func newOptions(opts []Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithoutDefaultCharset makes the decoder leave the charset parameter
+// unset for Data URIs without media type, instead of setting it to
+// the US-ASCII default of RFC 2397.
+func WithoutDefaultCharset() Option {
+	return func(o *options) {
+		o.noCharset = true
+	}
+}
+
+// WithDefaultMediaType makes the decoder use mt, e.g. application/octet-stream,
+// as the media type of the Data URIs without one, instead of the
+// text/plain;charset=US-ASCII default of RFC 2397. The parameters of
+// the Data URI, like "data:;name=a,", are added to those of mt.
+func WithDefaultMediaType(mt MediaType) Option {
+	return func(o *options) {
+		o.mediaType = &mt
+	}
+}
+
+// initialMediaType returns the media type of a decoded DataURI
+// before its header is parsed, the default one.
+func (o *options) initialMediaType() MediaType {
+	mt := defaultMediaType()
+	if o.mediaType != nil {
+		mt = MediaType{
+			Type:    o.mediaType.Type,
+			Subtype: o.mediaType.Subtype,
+			Params:  make(map[string]string, len(o.mediaType.Params)),
+		}
+		for k, v := range o.mediaType.Params {
+			mt.Params[k] = v
+		}
+	}
+	if o.noCharset {
+		delete(mt.Params, "charset")
+	}
+	return mt
+}
+
+// CharsetPolicy tells what the decoder does with the charset of the
+// default media type, US-ASCII, when a Data URI of a text media type
+// has no charset parameter, see WithCharsetPolicy.
+type CharsetPolicy int
+
+// Charset policies. The zero value is CharsetDrop.
+const (
+	// CharsetDrop leaves the charset unset: the default media type,
+	// with its charset, is replaced by the one of the Data URI, so
+	// "data:text/plain,a" has no charset unlike "data:,a".
+	CharsetDrop CharsetPolicy = iota
+	// CharsetKeep sets the charset to the default one.
+	CharsetKeep
+	// CharsetRequire makes the decoder fail with ErrInvalidParam.
+	CharsetRequire
+)
+
+// WithCharsetPolicy sets what the decoder does with the default charset
+// when a Data URI of a text media type, like "data:text/plain,a", has no
+// charset parameter. Media types of other types never get the default
+// charset, and the charset parameter of a Data URI always prevails.
+func WithCharsetPolicy(policy CharsetPolicy) Option {
+	return func(o *options) {
+		o.charset = policy
+	}
+}
+
+// WithRoundTrip makes the decoded DataURI remember its source string,
+// so that String, WriteTo and MarshalText reproduce it byte for byte
+// as long as the DataURI is left unmodified. This preserves the order
+// and quoting of parameters, the exact escape sequences and the
+// padding of the payload, as required e.g. to verify signatures.
+//
+// The payload is deemed unmodified as long as Data is the same slice, so
+// that writing the DataURI does not read it: set Data to a new slice
+// rather than modifying it in place. Copies of the DataURI do not
+// remember the source string.
+func WithRoundTrip() Option {
+	return func(o *options) {
+		o.roundTrip = true
+	}
+}
+
+// EncodeOption configures how a DataURI is written by StringWith and WriteToWith.
+type EncodeOption func(*encodeOptions)
+
+type encodeOptions struct {
+	alwaysEmitMediaType bool
+	alwaysEmitCharset   bool
+	stripMetadata       bool
+	version             int
+}
+
+func newEncodeOptions(opts []EncodeOption) *encodeOptions {
+	o := &encodeOptions{version: Version1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// AlwaysEmitMediaType writes the default text/plain media type
+// when the DataURI has none, instead of omitting it.
+func AlwaysEmitMediaType() EncodeOption {
+	return func(o *encodeOptions) {
+		o.alwaysEmitMediaType = true
+	}
+}
+
+// AlwaysEmitCharset writes the default US-ASCII charset parameter
+// when a DataURI of a text media type has none, instead of omitting it.
+func AlwaysEmitCharset() EncodeOption {
+	return func(o *encodeOptions) {
+		o.alwaysEmitCharset = true
+	}
+}
@@ -0,0 +1,343 @@
+package datauri
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTooLarge is matched by the Violations of code CodeDataTooLarge,
+// with errors.Is, e.g. for the payloads rejected by WithMaxDataSize.
+var ErrTooLarge = errors.New("datauri: data too large")
+
+// ViolationCode is a stable, machine-readable identifier
+// of the rule broken by a Violation.
+type ViolationCode string
+
+// Codes of the violations reported by Policy.
+const (
+	CodeDataTooLarge        ViolationCode = "data_too_large"
+	CodeTooManyParams       ViolationCode = "too_many_params"
+	CodeMediaTypeNotAllowed ViolationCode = "media_type_not_allowed"
+	CodeHeaderTooLarge      ViolationCode = "header_too_large"
+)
+
+// Codes of the warnings reported by Lint.
+const (
+	CodeCharsetOnBinary ViolationCode = "charset_on_binary"
+	CodeNeedlessBase64  ViolationCode = "needless_base64"
+)
+
+// Severity tells whether a Violation makes a DataURI unacceptable
+// or only reports an incoherence.
+type Severity int
+
+// Severities of a Violation. The zero value is SeverityError.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// String implements the Stringer interface.
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Violation describes a rule of a Policy broken by a DataURI.
+type Violation struct {
+	// Code identifies the broken rule.
+	Code ViolationCode
+	// Field is the part of the Data URI at fault:
+	// "data", "params", "mediatype" or "header".
+	Field string
+	// Limit is the limit set by the rule, e.g. a maximum size
+	// or the list of allowed media types.
+	Limit any
+	// Actual is the offending value.
+	Actual any
+	// Severity is SeverityError for the rules of a Policy
+	// and SeverityWarning for the checks of Lint.
+	Severity Severity
+}
+
+// Error implements the error interface.
+func (v Violation) Error() string {
+	if v.Severity == SeverityWarning {
+		return fmt.Sprintf("datauri: warning: %s: %s is %v", v.Code, v.Field, v.Actual)
+	}
+	return fmt.Sprintf("datauri: %s: %s is %v, limit is %v", v.Code, v.Field, v.Actual, v.Limit)
+}
+
+// Is reports whether v matches target: ErrTooLarge
+// is matched by the violations of code CodeDataTooLarge.
+func (v Violation) Is(target error) bool {
+	return target == ErrTooLarge && v.Code == CodeDataTooLarge
+}
+
+// Violations is a list of Violation, returned as an error by Policy.Check.
+type Violations []Violation
+
+// Error implements the error interface.
+func (vs Violations) Error() string {
+	msgs := make([]string, len(vs))
+	for i, v := range vs {
+		msgs[i] = v.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the violations of vs, for errors.Is and errors.As.
+func (vs Violations) Unwrap() []error {
+	errs := make([]error, len(vs))
+	for i, v := range vs {
+		errs[i] = v
+	}
+	return errs
+}
+
+// Policy holds the limits a DataURI must satisfy.
+// The zero value of each field means no limit.
+type Policy struct {
+	// MaxDataSize is the maximum size of the decoded payload, in bytes.
+	MaxDataSize int64
+	// MaxParams is the maximum number of media type parameters.
+	MaxParams int
+	// MaxHeaderSize is the maximum size of the header, the part of the
+	// serialized Data URI up to and including the data comma, in bytes.
+	// It bounds the size of the parameter values. The default media
+	// type and charset, when omitted, are not counted.
+	MaxHeaderSize int
+	// AllowedMediaTypes lists the patterns of the accepted media types,
+	// as for WithAllowedMediaTypes, see CompileMatcher.
+	AllowedMediaTypes []string
+}
+
+// Check returns the rules of p broken by du, as Violations,
+// or nil if du satisfies all of them. It fails with the error
+// of CompileMatcher if AllowedMediaTypes holds an invalid pattern.
+func (p *Policy) Check(du *DataURI) error {
+	var vs Violations
+	if p.MaxDataSize > 0 && int64(len(du.Data)) > p.MaxDataSize {
+		vs = append(vs, Violation{
+			Code:   CodeDataTooLarge,
+			Field:  "data",
+			Limit:  p.MaxDataSize,
+			Actual: int64(len(du.Data)),
+		})
+	}
+	if p.MaxParams > 0 && len(du.Params) > p.MaxParams {
+		vs = append(vs, Violation{
+			Code:   CodeTooManyParams,
+			Field:  "params",
+			Limit:  p.MaxParams,
+			Actual: len(du.Params),
+		})
+	}
+	if size := headerSize(du); p.MaxHeaderSize > 0 && size > p.MaxHeaderSize {
+		vs = append(vs, p.headerViolation(size))
+	}
+	if len(p.AllowedMediaTypes) > 0 {
+		m, err := CompileMatcher(p.AllowedMediaTypes...)
+		if err != nil {
+			return err
+		}
+		if !m.Match(&du.MediaType) {
+			vs = append(vs, Violation{
+				Code:   CodeMediaTypeNotAllowed,
+				Field:  "mediatype",
+				Limit:  p.AllowedMediaTypes,
+				Actual: du.ContentType(),
+			})
+		}
+	}
+	if len(vs) == 0 {
+		return nil
+	}
+	return vs
+}
+
+// CheckHeader checks the header of the Data URI string s against
+// MaxHeaderSize, before s is decoded, so that Data URIs with oversized
+// parameters are rejected early. Only the first MaxHeaderSize+1 bytes
+// of s are read.
+func (p *Policy) CheckHeader(s string) error {
+	if p.MaxHeaderSize <= 0 {
+		return nil
+	}
+	if len(s) > p.MaxHeaderSize+1 {
+		s = s[:p.MaxHeaderSize+1]
+	}
+	switch i := dataCommaIndex(s); {
+	case i < 0 && len(s) > p.MaxHeaderSize:
+		return Violations{p.headerViolation(len(s))}
+	case i+1 > p.MaxHeaderSize:
+		return Violations{p.headerViolation(i + 1)}
+	}
+	return nil
+}
+
+func (p *Policy) headerViolation(size int) Violation {
+	return Violation{
+		Code:   CodeHeaderTooLarge,
+		Field:  "header",
+		Limit:  p.MaxHeaderSize,
+		Actual: size,
+	}
+}
+
+// headerSize returns the size of the header of du, measured as by
+// CheckHeader: as decoded, if du was decoded WithRoundTrip and its
+// header is unmodified, or else as written by Version2, without the
+// default text/plain media type and US-ASCII charset.
+func headerSize(du *DataURI) int {
+	if orig := originalOf(du); orig != nil && orig.matchesHeader(du) {
+		return dataCommaIndex(orig.s) + 1
+	}
+	mt, omitted := mediaTypeV2(du.MediaType)
+	size := len("data:,")
+	if omitted {
+		size += len(mt.paramsString())
+	} else {
+		size += len(mt.String())
+	}
+	if du.Encoding == EncodingBase64 {
+		size += len(";base64")
+	}
+	return size
+}
+
+// Lint returns the incoherent combinations of fields of du, as Violations
+// of SeverityWarning, or nil if there is none:
+//   - a charset parameter on a binary media type, like image/png,
+//   - a base64 encoding for a printable ASCII payload which would be shorter
+//     in ASCII encoding.
+//
+// Unlike the rules of a Policy, warnings do not make du invalid.
+func Lint(du *DataURI) Violations {
+	var vs Violations
+	if charset, ok := du.Params["charset"]; ok && isBinaryMediaType(&du.MediaType) {
+		vs = append(vs, Violation{
+			Code:     CodeCharsetOnBinary,
+			Field:    "params",
+			Actual:   charset,
+			Severity: SeverityWarning,
+		})
+	}
+	if du.Encoding == EncodingBase64 && isPrintableASCII(du.Data) &&
+		len(EscapeString(string(du.Data))) < base64.StdEncoding.EncodedLen(len(du.Data)) {
+		vs = append(vs, Violation{
+			Code:     CodeNeedlessBase64,
+			Field:    "encoding",
+			Actual:   du.Encoding,
+			Severity: SeverityWarning,
+		})
+	}
+	return vs
+}
+
+// isBinaryMediaType reports whether mt is a media type
+// whose content is not text, so has no charset.
+func isBinaryMediaType(mt *MediaType) bool {
+	if isCompressedMediaType(mt) {
+		return true
+	}
+	t, st := strings.ToLower(mt.Type), strings.ToLower(mt.Subtype)
+	switch t {
+	case "image":
+		return st != "svg+xml"
+	case "font":
+		return true
+	case "application":
+		switch st {
+		case "octet-stream", "pdf", "wasm":
+			return true
+		}
+	}
+	return false
+}
+
+func isPrintableASCII(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return len(data) > 0
+}
+
+// WithMaxDataSize makes the decoder fail with a Violation of code
+// CodeDataTooLarge, matching ErrTooLarge, for payloads larger than
+// n bytes, once decoded, so that Data URIs from untrusted clients
+// are rejected without decoding them:
+//   - DecodeString checks the size before the payload is decoded,
+//   - Decode stops reading once the input is too large to hold
+//     a payload of n bytes or less, in which case the Actual size of
+//     the Violation is only a lower bound,
+//   - the Reader of NewReader fails once it has read more than n bytes.
+func WithMaxDataSize(n int64) Option {
+	return func(o *options) {
+		o.maxDataSize = n
+	}
+}
+
+// WithAllowedMediaTypes makes the decoder fail with a Violation of code
+// CodeMediaTypeNotAllowed for media types not matching patterns, see
+// CompileMatcher. The media type is checked before the payload is decoded,
+// and by Decode and NewReader before it is read.
+func WithAllowedMediaTypes(patterns ...string) Option {
+	return func(o *options) {
+		o.mediaTypes, o.err = CompileMatcher(patterns...)
+	}
+}
+
+func (o *options) checkMediaType(mt *MediaType) error {
+	if o.mediaTypes == nil || o.mediaTypes.Match(mt) {
+		return nil
+	}
+	return Violation{
+		Code:   CodeMediaTypeNotAllowed,
+		Field:  "mediatype",
+		Limit:  o.mediaTypes.patterns,
+		Actual: mt.ContentType(),
+	}
+}
+
+func (o *options) checkDataSize(size int64) error {
+	if size <= o.maxDataSize {
+		return nil
+	}
+	return Violation{
+		Code:   CodeDataTooLarge,
+		Field:  "data",
+		Limit:  o.maxDataSize,
+		Actual: size,
+	}
+}
+
+// maxEncodedSize returns the size of the largest Data URI holding
+// a payload of n bytes, ignoring the line breaks of base64 payloads:
+// a maximum size header, then a payload of escape sequences.
+func maxEncodedSize(n int64) int64 {
+	return maxHeaderSize + 3*n + 4
+}
+
+// payloadSize returns the size of the encoded payload s once decoded,
+// without decoding it. It is exact for valid payloads, padded or not.
+func payloadSize[T input](s T, encoding string) int64 {
+	if encoding != EncodingBase64 {
+		return int64(len(s) - 2*countByte(s, '%'))
+	}
+	s = trimLineBreaks(s)
+	n := len(s) - countByte(s, '\n') - countByte(s, '\r')
+	if hasSuffix(s, "==") {
+		n -= 2
+	} else if hasSuffix(s, "=") {
+		n--
+	}
+	// 6 bits per character, the unpadded trailing bits are dropped
+	return int64(n) * 6 / 8
+}
@@ -0,0 +1,392 @@
+package datauri
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		Policy        Policy
+		DataURI       *DataURI
+		ExpectedCodes []ViolationCode
+	}{
+		{
+			Policy{},
+			New([]byte("heya"), "text/plain", "charset", "utf-8"),
+			nil,
+		},
+		{
+			Policy{MaxDataSize: 4, MaxParams: 1, AllowedMediaTypes: []string{"text/plain"}},
+			New([]byte("heya"), "text/plain", "charset", "utf-8"),
+			nil,
+		},
+		{
+			Policy{MaxDataSize: 3},
+			New([]byte("heya"), "text/plain"),
+			[]ViolationCode{CodeDataTooLarge},
+		},
+		{
+			Policy{MaxParams: 1},
+			New([]byte("heya"), "text/plain", "charset", "utf-8", "name", "foo"),
+			[]ViolationCode{CodeTooManyParams},
+		},
+		{
+			Policy{AllowedMediaTypes: []string{"image/*", "application/pdf"}},
+			New([]byte("heya"), "IMAGE/png"),
+			nil,
+		},
+		{
+			Policy{AllowedMediaTypes: []string{"image/*", "application/pdf"}},
+			New([]byte("heya"), "application/PDF"),
+			nil,
+		},
+		{
+			Policy{MaxDataSize: 1, AllowedMediaTypes: []string{"image/*", "application/pdf"}},
+			New([]byte("heya"), "text/plain"),
+			[]ViolationCode{CodeDataTooLarge, CodeMediaTypeNotAllowed},
+		},
+	}
+	for _, test := range tests {
+		err := test.Policy.Check(test.DataURI)
+		if test.ExpectedCodes == nil {
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			continue
+		}
+		var vs Violations
+		if !errors.As(err, &vs) {
+			t.Errorf("Expected Violations, got %v", err)
+			continue
+		}
+		var codes []ViolationCode
+		for _, v := range vs {
+			codes = append(codes, v.Code)
+		}
+		if !reflect.DeepEqual(codes, test.ExpectedCodes) {
+			t.Errorf("Expected %v, got %v", test.ExpectedCodes, codes)
+		}
+	}
+}
+
+func TestViolationError(t *testing.T) {
+	err := (&Policy{MaxDataSize: 3}).Check(New([]byte("heya"), "text/plain"))
+	expected := "datauri: data_too_large: data is 4, limit is 3"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		DataURI       *DataURI
+		ExpectedCodes []ViolationCode
+	}{
+		{New([]byte{0x89, 'P', 'N', 'G'}, "image/png"), nil},
+		{New([]byte{0x89, 'P', 'N', 'G'}, "image/png", "charset", "utf-8"), []ViolationCode{CodeCharsetOnBinary}},
+		{New([]byte("<svg/>"), "image/svg+xml", "charset", "utf-8"), nil},
+		{New([]byte("heya"), "text/plain", "charset", "utf-8"), []ViolationCode{CodeNeedlessBase64}},
+		{New([]byte("héyà"), "text/plain", "charset", "utf-8"), nil},
+		{&DataURI{MediaType: defaultMediaType(), Encoding: EncodingASCII, Data: []byte("heya")}, nil},
+		{New([]byte("heya"), "application/octet-stream", "charset", "utf-8"), []ViolationCode{CodeCharsetOnBinary, CodeNeedlessBase64}},
+	}
+	for _, test := range tests {
+		vs := Lint(test.DataURI)
+		var codes []ViolationCode
+		for _, v := range vs {
+			if v.Severity != SeverityWarning {
+				t.Errorf("Expected %v, got %v", SeverityWarning, v.Severity)
+			}
+			codes = append(codes, v.Code)
+		}
+		if !reflect.DeepEqual(codes, test.ExpectedCodes) {
+			t.Errorf("%s: expected %v, got %v", test.DataURI, test.ExpectedCodes, codes)
+		}
+	}
+}
+
+func TestWarningError(t *testing.T) {
+	vs := Lint(New([]byte{0}, "image/png", "charset", "utf-8"))
+	expected := "datauri: warning: charset_on_binary: params is utf-8"
+	if len(vs) != 1 || vs.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, vs)
+	}
+}
+
+func TestPolicyMaxHeaderSize(t *testing.T) {
+	p := Policy{MaxHeaderSize: 40}
+	if err := p.Check(New([]byte("heya"), "text/plain", "name", "foo")); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	du := New([]byte("heya"), "text/plain", "name", strings.Repeat("a", 100))
+	expected := "datauri: header_too_large: header is 119, limit is 40"
+	if err := p.Check(du); err == nil || err.Error() != expected {
+		t.Errorf("Expected %s, got %v", expected, err)
+	}
+
+	tests := []struct {
+		Input    string
+		Expected bool
+	}{
+		{`data:text/plain;name=foo;base64,` + strings.Repeat("aGV5", 100), true},
+		{`data:text/plain;name=` + strings.Repeat("a", 100) + `;base64,aGV5YQ==`, false},
+		{`data:text/plain;name=` + strings.Repeat("a", 100), false},
+		{`data:text/plain;name="a,b"`, true},
+	}
+	for _, test := range tests {
+		if err := p.CheckHeader(test.Input); (err == nil) != test.Expected {
+			t.Errorf("%.40s: unexpected error %v", test.Input, err)
+		}
+	}
+
+	p = Policy{MaxHeaderSize: 10}
+	for _, test := range []struct {
+		Input    string
+		Expected bool
+	}{
+		{`data:;a=b,x`, true},
+		{`data:;a=bc,x`, false},
+		{`data:;a=bcd`, false},
+		{`data:,x`, true},
+		{`data:text/plain;charset=US-ASCII,x`, false},
+		{`data:text/plain,x`, false},
+		{`data:;base64,eA==`, false},
+	} {
+		if err := p.CheckHeader(test.Input); (err == nil) != test.Expected {
+			t.Errorf("%s: unexpected error %v", test.Input, err)
+		}
+		// Check measures the header as CheckHeader,
+		// decoded with or without WithRoundTrip.
+		du, err := DecodeString(test.Input, WithRoundTrip())
+		if err != nil {
+			continue
+		}
+		if err := p.Check(du); (err == nil) != test.Expected {
+			t.Errorf("%s: unexpected error %v", test.Input, err)
+		}
+		if test.Expected {
+			du, _ = DecodeString(test.Input)
+			if err := p.Check(du); err != nil {
+				t.Errorf("%s: unexpected error %v", test.Input, err)
+			}
+		}
+	}
+}
+
+func TestDecodeOptions(t *testing.T) {
+	tests := []struct {
+		Input        string
+		Opts         []Option
+		ExpectedCode ViolationCode
+	}{
+		{`data:text/plain;base64,aGV5YQ==`, []Option{WithMaxDataSize(4)}, ""},
+		{`data:text/plain;base64,aGV5YQ==`, []Option{WithMaxDataSize(3)}, CodeDataTooLarge},
+		{"data:text/plain;base64,aGV5\r\nYQ==\r\n", []Option{WithMaxDataSize(4)}, ""},
+		{`data:,A%20brief%20note`, []Option{WithMaxDataSize(12)}, ""},
+		{`data:,A%20brief%20note`, []Option{WithMaxDataSize(11)}, CodeDataTooLarge},
+		{`data:image/png;base64,aGV5YQ==`, []Option{WithAllowedMediaTypes("image/*")}, ""},
+		{`data:,heya`, []Option{WithAllowedMediaTypes("image/*")}, CodeMediaTypeNotAllowed},
+	}
+	for _, test := range tests {
+		_, err := DecodeString(test.Input, test.Opts...)
+		var v Violation
+		switch {
+		case test.ExpectedCode == "" && err != nil:
+			t.Errorf("%q: expected no error, got %v", test.Input, err)
+		case test.ExpectedCode != "" && !errors.As(err, &v):
+			t.Errorf("%q: expected Violation, got %v", test.Input, err)
+		case test.ExpectedCode != "" && v.Code != test.ExpectedCode:
+			t.Errorf("%q: expected %s, got %s", test.Input, test.ExpectedCode, v.Code)
+		}
+	}
+
+	if _, err := DecodeString(`data:,heya`, WithAllowedMediaTypes("image")); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+}
+
+func TestAllowedMediaTypesBeforeReading(t *testing.T) {
+	errRead := errors.New("payload read")
+	payload := func(header string) io.Reader {
+		return io.MultiReader(strings.NewReader(header), iotest.ErrReader(errRead))
+	}
+	opt := WithAllowedMediaTypes("image/*")
+
+	var v Violation
+	if _, err := Decode(payload("data:application/pdf;base64,"), opt); !errors.As(err, &v) || v.Code != CodeMediaTypeNotAllowed {
+		t.Errorf("Decode: expected %s, got %v", CodeMediaTypeNotAllowed, err)
+	}
+	if _, err := NewReader(payload("data:application/pdf;base64,"), opt); !errors.As(err, &v) || v.Code != CodeMediaTypeNotAllowed {
+		t.Errorf("NewReader: expected %s, got %v", CodeMediaTypeNotAllowed, err)
+	}
+	if _, err := Decode(payload("data:image/png;base64,"), opt); !errors.Is(err, errRead) {
+		t.Errorf("Decode: expected %v, got %v", errRead, err)
+	}
+
+	du, err := Decode(strings.NewReader("data:image/png;base64,aGV5YQ=="), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(du.Data) != "heya" {
+		t.Errorf("Expected %s, got %s", "heya", du.Data)
+	}
+}
+
+func TestErrTooLarge(t *testing.T) {
+	const n = 1 << 10
+	small := New(make([]byte, n), "application/octet-stream").String()
+	large := New(make([]byte, 100*n), "application/octet-stream").String()
+
+	if _, err := DecodeString(large, WithMaxDataSize(n)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+	if _, err := DecodeString(small, WithMaxDataSize(n)); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Decode stops reading early
+	r := strings.NewReader(large + strings.Repeat("A", 100*n))
+	if _, err := Decode(r, WithMaxDataSize(n)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+	if read := r.Size() - int64(r.Len()); read > maxEncodedSize(n)+1 {
+		t.Errorf("Expected at most %d bytes read, got %d", maxEncodedSize(n)+1, read)
+	}
+	if _, err := Decode(strings.NewReader(large), WithMaxDataSize(n)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+	if _, err := Decode(strings.NewReader(small), WithMaxDataSize(n)); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	dr, err := NewReader(strings.NewReader(large), WithMaxDataSize(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(io.Discard, dr); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+	dr, err = NewReader(strings.NewReader(small), WithMaxDataSize(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(io.Discard, dr); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	p := Policy{MaxDataSize: 1, MaxParams: 1}
+	err = p.Check(New([]byte("heya"), "text/plain", "a", "1", "b", "2"))
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+	var v Violation
+	if !errors.As(err, &v) || v.Code != CodeDataTooLarge {
+		t.Errorf("Expected %s, got %v", CodeDataTooLarge, err)
+	}
+}
+
+func TestWithoutDefaultCharset(t *testing.T) {
+	du, err := DecodeString(`data:,heya`, WithoutDefaultCharset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := du.Params["charset"]; ok {
+		t.Errorf("Expected no charset, got %v", du.Params)
+	}
+	du, err = DecodeString(`data:;charset=utf-8,heya`, WithoutDefaultCharset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if du.Params["charset"] != "utf-8" {
+		t.Errorf("Expected %s, got %v", "utf-8", du.Params)
+	}
+}
+
+func TestWithDefaultMediaType(t *testing.T) {
+	octetStream := MediaType{Type: "application", Subtype: "octet-stream"}
+	tests := []struct {
+		InputString string
+		Default     MediaType
+		Expected    string
+	}{
+		{`data:,heya`, octetStream, "application/octet-stream"},
+		{`data:;base64,aGV5YQ==`, octetStream, "application/octet-stream"},
+		{`data:;name=a,heya`, octetStream, "application/octet-stream;name=a"},
+		{`data:text/html,heya`, octetStream, "text/html"},
+		{`data:,heya`, MediaType{"text", "plain", map[string]string{"charset": "utf-8"}}, "text/plain;charset=utf-8"},
+		{`data:;charset=iso-8859-1,heya`, MediaType{"text", "plain", map[string]string{"charset": "utf-8"}}, "text/plain;charset=iso-8859-1"},
+		{`data:text/html,heya`, MediaType{"text", "plain", map[string]string{"charset": "utf-8", "name": "a"}}, "text/html"},
+	}
+	for _, test := range tests {
+		for _, decode := range []func(string, ...Option) (*DataURI, error){
+			DecodeString,
+			func(s string, opts ...Option) (*DataURI, error) { return DecodeBytes([]byte(s), opts...) },
+		} {
+			du, err := decode(test.InputString, WithDefaultMediaType(test.Default))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := du.MediaType.String(); got != test.Expected {
+				t.Errorf("%s: expected %s, got %s", test.InputString, test.Expected, got)
+			}
+		}
+	}
+
+	// the parameters of the default media type are copied
+	utf8 := MediaType{"text", "plain", map[string]string{"charset": "utf-8"}}
+	if _, err := DecodeString(`data:;charset=iso-8859-1,heya`, WithDefaultMediaType(utf8)); err != nil {
+		t.Fatal(err)
+	}
+	if utf8.Params["charset"] != "utf-8" {
+		t.Errorf("Expected %s, got %v", "utf-8", utf8.Params)
+	}
+}
+
+func TestWithCharsetPolicy(t *testing.T) {
+	tests := []struct {
+		InputString string
+		Policy      CharsetPolicy
+		Expected    string
+		Err         string
+	}{
+		{`data:text/plain,a`, CharsetDrop, "text/plain", ""},
+		{`data:text/plain,a`, CharsetKeep, "text/plain;charset=US-ASCII", ""},
+		{`data:text/plain,a`, CharsetRequire, "", "missing charset parameter at offset 15"},
+		{`data:text/plain;charset=utf-8,a`, CharsetKeep, "text/plain;charset=utf-8", ""},
+		{`data:text/plain;CHARSET=utf-8,a`, CharsetRequire, "text/plain;CHARSET=utf-8", ""},
+		{`data:;charset=utf-8,a`, CharsetRequire, "text/plain;charset=utf-8", ""},
+		{`data:,a`, CharsetRequire, "text/plain;charset=US-ASCII", ""},
+		{`data:image/png,a`, CharsetKeep, "image/png", ""},
+		{`data:image/png,a`, CharsetRequire, "image/png", ""},
+		{`data:TEXT/html;name=a,a`, CharsetKeep, "text/html;charset=US-ASCII;name=a", ""},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.InputString, WithCharsetPolicy(test.Policy))
+		if test.Err != "" {
+			if err == nil || err.Error() != test.Err || !errors.Is(err, ErrInvalidParam) {
+				t.Errorf("%s: expected %s, got %v", test.InputString, test.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.InputString, err)
+			continue
+		}
+		if got := du.MediaType.String(); got != test.Expected {
+			t.Errorf("%s: expected %s, got %s", test.InputString, test.Expected, got)
+		}
+	}
+
+	du, err := DecodeString(`data:text/plain,a`, WithCharsetPolicy(CharsetKeep), WithoutDefaultCharset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := du.Params["charset"]; ok {
+		t.Errorf("Expected no charset, got %v", du.Params)
+	}
+}
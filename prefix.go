@@ -0,0 +1,240 @@
+package datauri
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// State is the progress of the incremental validation of a Data URI
+// arriving in chunks, see ValidatePrefix. The zero value is the state
+// before any byte has arrived, validated with the default options.
+//
+// A State is a value: Next returns a new State and leaves its receiver
+// unmodified, so that a proxy can keep the last valid one around.
+type State struct {
+	// Offset is the number of bytes validated so far.
+	Offset int
+	// Header is the DataURI described by the header, with an empty
+	// payload, once the data comma has arrived, or nil.
+	Header *DataURI
+	// Size is the size of the payload decoded so far.
+	Size int64
+
+	opts   *options
+	header string // header received so far, before the data comma
+
+	// ASCII payload: escape sequence started and not yet complete
+	escape    string
+	escOffset int
+
+	// base64 payload
+	chars    int64 // of the alphabet, excluding the padding
+	padding  int
+	alphabet byte // '+' or '-' once seen
+	cr       bool // last byte was '\r'
+}
+
+// ValidatePrefix validates b, the first bytes of a Data URI, and
+// returns the State of the validation, to resume it with Next as the
+// following bytes arrive. This lets gateways reject malformed Data
+// URIs, or those whose media type or size violate opts (see
+// WithAllowedMediaTypes, WithMaxDataSize and WithStrictRFC2397),
+// before the full body is received, without buffering the payload.
+//
+// A nil error means that b may be continued into a valid Data URI;
+// use Done once the input has ended.
+func ValidatePrefix(b []byte, opts ...Option) (State, error) {
+	return State{opts: newOptions(opts)}.Next(b)
+}
+
+// Next validates b, the bytes following those validated by s,
+// and returns the resulting State.
+// The offsets of the errors are relative to the whole input.
+func (s State) Next(b []byte) (State, error) {
+	if s.opts == nil {
+		s.opts = newOptions(nil)
+	}
+	if s.opts.err != nil {
+		return s, s.opts.err
+	}
+	if s.Header == nil {
+		var err error
+		if b, err = s.nextHeader(b); err != nil || s.Header == nil {
+			return s, err
+		}
+	}
+	var err error
+	if s.Header.Encoding == EncodingBase64 {
+		err = s.nextBase64(b)
+	} else {
+		err = s.nextASCII(b)
+	}
+	if err != nil {
+		return s, err
+	}
+	if s.opts.maxDataSize > 0 {
+		return s, s.opts.checkDataSize(s.Size)
+	}
+	return s, nil
+}
+
+// Done reports whether the input validated by s is a complete
+// Data URI, returning the error the decoder would return otherwise.
+func (s State) Done() error {
+	if s.opts == nil {
+		s.opts = newOptions(nil)
+	}
+	if s.Header == nil {
+		return headerError(s.header)
+	}
+	if s.escape != "" {
+		return &EscapeError{Offset: s.escOffset, Sequence: s.escape}
+	}
+	if s.Header.Encoding != EncodingBase64 {
+		return nil
+	}
+	switch n := int(s.chars % 4); {
+	case n == 1:
+		return base64.CorruptInputError(s.Offset)
+	case n != 0 && s.padding == 0:
+		return s.opts.correct(Correction{
+			Offset:      s.Offset,
+			Kind:        CorrectionMissingPadding,
+			Replacement: strings.Repeat("=", 4-n),
+		})
+	case n != 0 && s.padding != 4-n:
+		return base64.CorruptInputError(s.Offset)
+	}
+	return nil
+}
+
+// nextHeader validates b while the data comma has not arrived,
+// and returns the part of b following it.
+func (s *State) nextHeader(b []byte) ([]byte, error) {
+	start := len(s.header)
+	s.header += string(b)
+	if !strings.HasPrefix(s.header, dataPrefix) {
+		if strings.HasPrefix(dataPrefix, s.header) {
+			s.Offset = len(s.header)
+			return nil, nil
+		}
+		return nil, headerError(s.header)
+	}
+	i := dataCommaIndex(s.header)
+	if i < 0 {
+		if len(s.header) > maxHeaderSize {
+			return nil, errors.New("datauri: header too large")
+		}
+		if _, err := DecodeString(s.header); !errors.Is(err, ErrMissingComma) {
+			return nil, err
+		}
+		s.Offset = len(s.header)
+		return nil, nil
+	}
+	header := s.header[:i+1]
+	du, err := decodeString(header, s.opts)
+	if err != nil {
+		return nil, err
+	}
+	s.Header, s.header, s.Offset = du, "", len(header)
+	return b[len(header)-start:], nil
+}
+
+func (s *State) nextASCII(b []byte) error {
+	for i, c := range b {
+		off := s.Offset + i
+		switch {
+		case s.escape != "":
+			s.escape += string(c)
+			if !isHex(c) {
+				return &EscapeError{Offset: s.escOffset, Sequence: s.escape}
+			}
+			if len(s.escape) == 3 {
+				s.escape = ""
+				s.Size++
+			}
+		case c == '%':
+			s.escape, s.escOffset = "%", off
+		default:
+			if !urlCharTable[c] {
+				if err := s.opts.correct(Correction{
+					Offset:      off,
+					Kind:        CorrectionUnescapedChar,
+					Original:    string(c),
+					Replacement: fmt.Sprintf("%%%02X", c),
+				}); err != nil {
+					return err
+				}
+			}
+			s.Size++
+		}
+	}
+	s.Offset += len(b)
+	return nil
+}
+
+func (s *State) nextBase64(b []byte) error {
+	for i, c := range b {
+		off := s.Offset + i
+		cr := s.cr
+		s.cr = c == '\r'
+		switch {
+		case c == '\n' && cr:
+			// second byte of a "\r\n" line break, already corrected
+		case c == '\r' || c == '\n':
+			lb := string(c)
+			if c == '\r' && i+1 < len(b) && b[i+1] == '\n' {
+				lb = "\r\n"
+			}
+			if err := s.opts.correct(Correction{
+				Offset:   off,
+				Kind:     CorrectionLineBreak,
+				Original: lb,
+			}); err != nil {
+				return err
+			}
+		case c == '=':
+			if n := int(s.chars % 4); n < 2 || s.padding == 4-n {
+				return base64.CorruptInputError(off)
+			}
+			s.padding++
+		case s.padding > 0:
+			return base64.CorruptInputError(off)
+		case c == '-' || c == '_':
+			if s.alphabet == '+' {
+				return base64.CorruptInputError(off)
+			}
+			if s.alphabet == 0 {
+				replacement := "+"
+				if c == '_' {
+					replacement = "/"
+				}
+				if err := s.opts.correct(Correction{
+					Offset:      off,
+					Kind:        CorrectionURLSafeBase64,
+					Original:    string(c),
+					Replacement: replacement,
+				}); err != nil {
+					return err
+				}
+			}
+			s.alphabet = '-'
+			s.chars++
+		case c == '+' || c == '/':
+			if s.alphabet == '-' {
+				return base64.CorruptInputError(off)
+			}
+			s.alphabet = '+'
+			s.chars++
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+			s.chars++
+		default:
+			return base64.CorruptInputError(off)
+		}
+	}
+	s.Offset += len(b)
+	s.Size = s.chars * 6 / 8
+	return nil
+}
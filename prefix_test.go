@@ -0,0 +1,141 @@
+package datauri
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestValidatePrefix(t *testing.T) {
+	valid := []string{
+		"data:,",
+		"data:,A%20brief%20note",
+		"data:text/plain;charset=utf-8;name=\"a,b\",hey",
+		"data:;base64,aGV5YQ==",
+		"data:image/gif;base64,R0lGOD\r\nlhAQABAIAAAP//",
+		"data:application/octet-stream;base64,-_8=",
+		"data:text/plain;base64,aGV5",
+	}
+	for _, s := range valid {
+		expected, err := DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i <= len(s); i++ {
+			st, err := ValidatePrefix([]byte(s[:i]))
+			if err != nil {
+				t.Errorf("%q split at %d: expected no error, got %v", s, i, err)
+				continue
+			}
+			st, err = st.Next([]byte(s[i:]))
+			if err != nil {
+				t.Errorf("%q split at %d: expected no error, got %v", s, i, err)
+				continue
+			}
+			if err := st.Done(); err != nil {
+				t.Errorf("%q split at %d: expected no error, got %v", s, i, err)
+			}
+			if st.Offset != len(s) {
+				t.Errorf("Expected %d, got %d", len(s), st.Offset)
+			}
+			if st.Size != int64(len(expected.Data)) {
+				t.Errorf("%q: expected size %d, got %d", s, len(expected.Data), st.Size)
+			}
+			if st.Header.MediaType.String() != expected.MediaType.String() {
+				t.Errorf("Expected %s, got %s", expected.MediaType.String(), st.Header.MediaType.String())
+			}
+		}
+	}
+}
+
+func TestValidatePrefixErrors(t *testing.T) {
+	tests := []struct {
+		prefix         string
+		expectedOffset int
+	}{
+		{"date:", -1},
+		{"data:text;", -1},
+		{"data:,A%2z", 7},
+		{"data:;base64,aGV5YQ==a", 21},
+		{"data:;base64,a=", 14},
+		{"data:;base64,aGV5Y===", 18},
+		{"data:;base64,aGV5YQ===", 21},
+		{"data:;base64,aG-+", 16},
+		{"data:;base64,aG!", 15},
+	}
+	for _, test := range tests {
+		_, err := ValidatePrefix([]byte(test.prefix))
+		if test.expectedOffset < 0 {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.prefix)
+			}
+			continue
+		}
+		var escErr *EscapeError
+		var b64Err base64.CorruptInputError
+		switch {
+		case errors.As(err, &escErr):
+			if escErr.Offset != test.expectedOffset {
+				t.Errorf("%q: expected offset %d, got %d", test.prefix, test.expectedOffset, escErr.Offset)
+			}
+		case errors.As(err, &b64Err):
+			if int(b64Err) != test.expectedOffset {
+				t.Errorf("%q: expected offset %d, got %d", test.prefix, test.expectedOffset, b64Err)
+			}
+		default:
+			t.Errorf("%q: expected an offset error, got %v", test.prefix, err)
+		}
+	}
+
+	// valid prefixes, but not complete Data URIs
+	for _, prefix := range []string{"", "dat", "data:text/pl", "data:,A%2", "data:;base64,a"} {
+		st, err := ValidatePrefix([]byte(prefix))
+		if err != nil {
+			t.Errorf("%q: expected no error, got %v", prefix, err)
+		}
+		if err := st.Done(); err == nil {
+			t.Errorf("%q: expected an error when done", prefix)
+		}
+	}
+}
+
+func TestValidatePrefixOptions(t *testing.T) {
+	st, err := ValidatePrefix([]byte("data:image/png;base64,"), WithAllowedMediaTypes("text/*"))
+	var v Violation
+	if !errors.As(err, &v) || v.Code != CodeMediaTypeNotAllowed {
+		t.Errorf("Expected %s, got %v", CodeMediaTypeNotAllowed, err)
+	}
+	if st.Header != nil {
+		t.Errorf("Expected no header, got %v", st.Header)
+	}
+
+	st, err = ValidatePrefix([]byte("data:,0123"), WithMaxDataSize(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Next([]byte("456")); !errors.As(err, &v) || v.Code != CodeDataTooLarge {
+		t.Errorf("Expected %s, got %v", CodeDataTooLarge, err)
+	}
+	if _, err := st.Next([]byte("%20")); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	_, err = ValidatePrefix([]byte("data:,a b"), WithStrictRFC2397())
+	if !errors.Is(err, ErrNotConformant) {
+		t.Errorf("Expected %v, got %v", ErrNotConformant, err)
+	}
+	var corrections []Correction
+	st, err = ValidatePrefix([]byte("data:;base64,aGV5"), WithCorrections(&corrections))
+	if err == nil {
+		st, err = st.Next([]byte("YQ"))
+	}
+	if err == nil {
+		err = st.Done()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 || corrections[0].Kind != CorrectionMissingPadding || corrections[0].Offset != 19 {
+		t.Errorf("Expected a missing padding at 19, got %v", corrections)
+	}
+}
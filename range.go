@@ -0,0 +1,123 @@
+package datauri
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	errInvalidRange = errors.New("datauri: invalid range")
+	errOutOfBounds  = errors.New("datauri: range out of bounds")
+)
+
+// DataRange returns at most length bytes of the payload of du,
+// starting at offset. Fewer bytes are returned if the payload ends before
+// offset+length. The returned slice shares memory with du.Data.
+func (du *DataURI) DataRange(offset, length int) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, errInvalidRange
+	}
+	if offset > len(du.Data) {
+		return nil, errOutOfBounds
+	}
+	// clamped before adding, offset+length may overflow
+	length = min(length, len(du.Data)-offset)
+	return du.Data[offset : offset+length], nil
+}
+
+// DataRange decodes at most length bytes of the payload of the Data URI
+// string s, starting at offset, without decoding the whole payload.
+//
+// For base64 payloads, only the 4 characters blocks covering the range
+// are decoded (and validated). ASCII payloads are unescaped up to the end
+// of the range.
+func DataRange(s string, offset, length int) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, errInvalidRange
+	}
+	du, payload, err := decodeHeader(s)
+	if err != nil {
+		return nil, err
+	}
+	if du.Encoding == EncodingBase64 {
+		return base64Range(payload, offset, length)
+	}
+	return asciiRange(payload, offset, length)
+}
+
+// base64DecodedSize returns the number of bytes encoded
+// in the base64 string s, and whether s is padded.
+func base64DecodedSize(s string) (size int, padded bool, err error) {
+	if !isPaddedBase64(s) {
+		if len(s)%4 == 1 {
+			return 0, false, base64.CorruptInputError(len(s))
+		}
+		return len(s) * 6 / 8, false, nil
+	}
+	if len(s)%4 != 0 {
+		return 0, true, base64.CorruptInputError(len(s))
+	}
+	n := len(s) / 4 * 3
+	if strings.HasSuffix(s, "==") {
+		n -= 2
+	} else if strings.HasSuffix(s, "=") {
+		n--
+	}
+	return n, true, nil
+}
+
+func base64Range(payload string, offset, length int) ([]byte, error) {
+	if strings.ContainsAny(payload, "\r\n") {
+		payload = strings.NewReplacer("\r", "", "\n", "").Replace(payload)
+	}
+	size, padded, err := base64DecodedSize(payload)
+	if err != nil {
+		return nil, err
+	}
+	if offset > size {
+		return nil, errOutOfBounds
+	}
+	end := offset + min(length, size-offset)
+
+	firstBlock, lastBlock := offset/3, (end+2)/3
+	chunk := payload[firstBlock*4 : min(lastBlock*4, len(payload))]
+	enc := base64Encoding(indexURLSafeBase64(payload) >= 0, padded)
+	data := make([]byte, enc.DecodedLen(len(chunk)))
+	n, err := enc.Decode(data, []byte(chunk))
+	if err != nil {
+		return nil, err
+	}
+	data = data[:n]
+	skip := firstBlock * 3
+	return data[offset-skip : end-skip], nil
+}
+
+func asciiRange(payload string, offset, length int) ([]byte, error) {
+	// the payload decodes to at most len(payload) bytes, length
+	// is clamped to it before adding it or allocating it
+	length = min(length, len(payload))
+	var (
+		data = make([]byte, 0, length)
+		end  = offset + length
+		k    int
+	)
+	for i := 0; i < len(payload) && k < end; i++ {
+		c := payload[i]
+		if c == '%' {
+			if i+2 >= len(payload) || !isHex(payload[i+1]) || !isHex(payload[i+2]) {
+				return nil, escapeError(payload, i)
+			}
+			c = unhex(payload[i+1])<<4 | unhex(payload[i+2])
+			i += 2
+		}
+		if k >= offset {
+			data = append(data, c)
+		}
+		k++
+	}
+	if k < offset {
+		return nil, errOutOfBounds
+	}
+	return data, nil
+}
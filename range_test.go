@@ -0,0 +1,113 @@
+package datauri
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestDataRange(t *testing.T) {
+	inputs := []string{
+		`data:text/plain;base64,VGhlIHF1aWNrIGJyb3duIGZveCBqdW1wcw==`,
+		`data:text/plain;base64,VGhlIHF1aWNrIGJyb3du` + "\n" + `IGZveCBqdW1wcw==`,
+		`data:text/plain;base64,VGhlIHF1aWNrIGJyb3duIGZveCBqdW1wcyE=`,
+		`data:text/plain;base64,VGhlIHF1aWNrIGJyb3duIGZveCBqdW1wcyEh`,
+		`data:text/plain;base64,VGhlIHF1aWNrIGJyb3duIGZveCBqdW1wcyE`,
+		`data:text/plain;base64,VGhlIHF1aWNrIGJyb3duIGZveCBqdW1wcw`,
+		`data:text/plain;base64,VGhlIHF1aWNrIGJyb3du` + "\r\n" + `IGZveCBqdW1wcw==` + "\r\n",
+		`data:text/plain,The%20quick%20brown%20fox%20jumps`,
+		`data:,`,
+	}
+	for _, s := range inputs {
+		du, err := DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for offset := 0; offset <= len(du.Data); offset++ {
+			for length := 0; length <= len(du.Data)-offset+2; length++ {
+				got, err := DataRange(s, offset, length)
+				if err != nil {
+					t.Errorf("%s [%d:+%d]: %v", s, offset, length, err)
+					continue
+				}
+				expected, _ := du.DataRange(offset, length)
+				if !bytes.Equal(got, expected) {
+					t.Errorf("%s [%d:+%d]: expected %q, got %q", s, offset, length, expected, got)
+				}
+			}
+		}
+		if _, err := DataRange(s, len(du.Data)+1, 1); err == nil {
+			t.Errorf("%s: expected out of bounds error", s)
+		}
+		if _, err := du.DataRange(len(du.Data)+1, 1); err == nil {
+			t.Errorf("%s: expected out of bounds error", s)
+		}
+	}
+}
+
+func TestDataRangeErrors(t *testing.T) {
+	tests := []struct {
+		Input  string
+		Offset int
+		Length int
+	}{
+		{`data:text/plain;base64,aGV5YQ==`, -1, 2},
+		{`data:text/plain;base64,aGV5YQ==`, 0, -2},
+		{`data:text/plain;base64,aGV5YQ=`, 0, 2},
+		{`data:text/plain;base64,a*V5YQ==`, 0, 2},
+		{`data:text/plain,A%2`, 0, 2},
+		{`data:text/plain,A%zzb`, 0, 3},
+		{`data:text/plain`, 0, 2},
+		{`text/plain,abc`, 0, 2},
+	}
+	for _, test := range tests {
+		if data, err := DataRange(test.Input, test.Offset, test.Length); err == nil {
+			t.Errorf("%s: expected error, got %q", test.Input, data)
+		}
+	}
+}
+
+func TestDataRangeDecodesOnlyCoveringBlocks(t *testing.T) {
+	// The last block is invalid but out of the requested range.
+	got, err := DataRange(`data:;base64,aGV5YQ==****`, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "ey" {
+		t.Errorf("Expected %q, got %q", "ey", got)
+	}
+}
+
+func TestDataRangeLargeLength(t *testing.T) {
+	inputs := []string{
+		`data:,hello`,
+		`data:;base64,aGVsbG8=`,
+	}
+	for _, s := range inputs {
+		du, err := DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range []struct {
+			Offset, Length int
+			Expected       string
+		}{
+			{0, math.MaxInt, "hello"},
+			{1, math.MaxInt, "ello"},
+			{0, 1 << 62, "hello"},
+			{5, math.MaxInt, ""},
+		} {
+			got, err := DataRange(s, test.Offset, test.Length)
+			if err != nil || string(got) != test.Expected {
+				t.Errorf("%s [%d:+%d]: expected %q, got %q, %v", s, test.Offset, test.Length, test.Expected, got, err)
+			}
+			got, err = du.DataRange(test.Offset, test.Length)
+			if err != nil || string(got) != test.Expected {
+				t.Errorf("%s [%d:+%d]: expected %q, got %q, %v", s, test.Offset, test.Length, test.Expected, got, err)
+			}
+		}
+		if _, err := DataRange(s, 6, math.MaxInt); err == nil {
+			t.Errorf("%s: expected out of bounds error", s)
+		}
+	}
+}
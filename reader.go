@@ -0,0 +1,96 @@
+package datauri
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Reader decodes the payload of a Data URI read from a stream,
+// without holding it in memory.
+type Reader struct {
+	MediaType
+	Encoding string
+
+	r io.Reader
+}
+
+// NewReader reads the header of the Data URI in r and returns a Reader
+// of its decoded payload. Use WithRateLimit to throttle the payload,
+// WithMaxDataSize to bound it, WithAllowedMediaTypes to reject a media
+// type before reading it, and WithStrictRFC2397 to reject the
+// characters not allowed in a URL.
+func NewReader(r io.Reader, opts ...Option) (*Reader, error) {
+	o := newOptions(opts)
+	br := bufio.NewReader(r)
+	header, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	du, err := decodeString(header, o)
+	if err != nil {
+		return nil, err
+	}
+
+	dr := &Reader{
+		MediaType: du.MediaType,
+		Encoding:  du.Encoding,
+	}
+	if du.Encoding == EncodingBase64 {
+		dr.r = newBase64Decoder(br, len(header))
+	} else {
+		dr.r = &unescapeReader{r: br, off: len(header), strict: o.strict}
+	}
+	if o.maxDataSize > 0 {
+		dr.r = &sizeCheckedReader{r: dr.r, o: o}
+	}
+	if o.rateLimit > 0 {
+		dr.r = newThrottledReader(dr.r, o.rateLimit)
+	}
+	return dr, nil
+}
+
+// Read implements the io.Reader interface.
+func (r *Reader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+// readHeader reads the header of the Data URI in br,
+// up to and including the data comma.
+func readHeader(br *bufio.Reader) (string, error) {
+	var buf []byte
+	for len(buf) <= maxHeaderSize {
+		chunk, err := br.ReadSlice(',')
+		buf = append(buf, chunk...)
+		if err == nil {
+			if dataCommaIndex(string(buf)) == len(buf)-1 {
+				return string(buf), nil
+			}
+			continue
+		}
+		if err == io.EOF {
+			return "", headerError(string(buf))
+		}
+		if err != bufio.ErrBufferFull {
+			return "", err
+		}
+	}
+	return "", errors.New("datauri: header too large")
+}
+
+// sizeCheckedReader fails with a Violation once more than
+// the maximum data size of o has been read from r.
+type sizeCheckedReader struct {
+	r    io.Reader
+	o    *options
+	size int64
+}
+
+func (r *sizeCheckedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.size += int64(n)
+	if err := r.o.checkDataSize(r.size); err != nil {
+		return n, err
+	}
+	return n, err
+}
@@ -0,0 +1,81 @@
+package datauri
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewReader(t *testing.T) {
+	tests := []string{
+		`data:,`,
+		`data:,A%20brief%20note`,
+		`data:text/plain;charset=utf-8;base64,aGV5YQ==`,
+		`data:text/plain;name="a, b";base64,aGV5YQ==`,
+		`data:;base64,aGV5` + "\n" + `YQ==`,
+		`data:image/png;base64,iVBORw0KGgo=`,
+		New(largePayload()[:100000], "application/octet-stream").String(),
+	}
+	for _, s := range tests {
+		du, err := DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := NewReader(iotest.OneByteReader(strings.NewReader(s)))
+		if err != nil {
+			t.Errorf("%.30s: %v", s, err)
+			continue
+		}
+		if r.MediaType.String() != du.MediaType.String() || r.Encoding != du.Encoding {
+			t.Errorf("Expected %s %s, got %s %s", du.MediaType.String(), du.Encoding, r.MediaType.String(), r.Encoding)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("%.30s: %v", s, err)
+		} else if !bytes.Equal(data, du.Data) {
+			t.Errorf("%.30s: expected %d bytes, got %d", s, len(du.Data), len(data))
+		}
+	}
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	tests := []struct {
+		Input  string
+		Offset int
+	}{
+		{`data:,A%2`, 7},
+		{`data:,A%zz`, 7},
+		{`data:,A brief note`, 7},
+	}
+	for _, test := range tests {
+		r, err := NewReader(strings.NewReader(test.Input), WithStrictRFC2397())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = io.ReadAll(r)
+		var escErr *EscapeError
+		if !errors.As(err, &escErr) {
+			t.Errorf("%s: expected EscapeError, got %v", test.Input, err)
+		} else if escErr.Offset != test.Offset {
+			t.Errorf("%s: expected offset %d, got %d", test.Input, test.Offset, escErr.Offset)
+		}
+	}
+
+	r, err := NewReader(strings.NewReader(`data:,A brief note`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(r); err != nil || string(data) != "A brief note" {
+		t.Errorf("Expected lenient decoding, got %q, %v", data, err)
+	}
+
+	if _, err := NewReader(strings.NewReader(`data:text/plain`)); !errors.Is(err, ErrMissingComma) {
+		t.Errorf("Expected %v, got %v", ErrMissingComma, err)
+	}
+	if _, err := NewReader(strings.NewReader(`data:;a=` + strings.Repeat("b", 2*maxHeaderSize) + `,`)); err == nil {
+		t.Error("Expected error")
+	}
+}
@@ -0,0 +1,167 @@
+package datauri
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+// Handle gives random access to the payload of a Data URI
+// stored in an io.ReaderAt, such as a memory-mapped file.
+// It implements io.ReaderAt over the decoded payload.
+type Handle struct {
+	MediaType
+	Encoding string
+
+	ra       io.ReaderAt
+	offset   int64 // of the encoded payload in ra
+	encSize  int64 // size of the encoded payload
+	dataSize int64 // size of the decoded payload
+	enc      *base64.Encoding
+}
+
+// OpenReaderAt reads the header of the Data URI of size bytes stored in ra
+// and returns a Handle to access its payload.
+//
+// Random access is only possible for base64 payloads without line
+// breaks: the 4 characters blocks covering each read are decoded.
+// The payload is scanned once, to detect its alphabet, the standard
+// or the URL-safe one, as by DecodeString. It may be unpadded.
+func OpenReaderAt(ra io.ReaderAt, size int64) (*Handle, error) {
+	header, err := readHeaderAt(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	du, err := DecodeString(header)
+	if err != nil {
+		return nil, err
+	}
+	if du.Encoding != EncodingBase64 {
+		return nil, errors.New("datauri: random access requires a base64 payload")
+	}
+
+	h := &Handle{
+		MediaType: du.MediaType,
+		Encoding:  du.Encoding,
+		ra:        ra,
+		offset:    int64(len(header)),
+		encSize:   size - int64(len(header)),
+	}
+	urlSafe, err := h.scanPayload()
+	if err != nil {
+		return nil, err
+	}
+	var tail [2]byte
+	if h.encSize > 0 {
+		t := tail[max(0, 2-h.encSize):]
+		if _, err := ra.ReadAt(t, size-int64(len(t))); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	padded := h.encSize%4 == 0 || tail[1] == '='
+	switch {
+	case padded && h.encSize%4 != 0, h.encSize%4 == 1:
+		return nil, base64.CorruptInputError(h.encSize)
+	case !padded:
+		h.dataSize = h.encSize * 6 / 8
+	case tail[0] == '=':
+		h.dataSize = h.encSize/4*3 - 2
+	case tail[1] == '=':
+		h.dataSize = h.encSize/4*3 - 1
+	default:
+		h.dataSize = h.encSize / 4 * 3
+	}
+	h.enc = base64Encoding(urlSafe, padded)
+	return h, nil
+}
+
+// scanPayload reports whether the payload of h holds characters of the
+// URL-safe base64 alphabet, and fails if it holds line breaks.
+func (h *Handle) scanPayload() (bool, error) {
+	urlSafe := false
+	chunk := make([]byte, 32<<10)
+	for off := int64(0); off < h.encSize; off += int64(len(chunk)) {
+		n, err := h.ra.ReadAt(chunk[:min(int64(len(chunk)), h.encSize-off)], h.offset+off)
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		if bytes.ContainsAny(chunk[:n], "\r\n") {
+			return false, errors.New("datauri: random access requires a payload without line breaks")
+		}
+		urlSafe = urlSafe || indexURLSafeBase64(chunk[:n]) >= 0
+		if n == 0 {
+			break
+		}
+	}
+	return urlSafe, nil
+}
+
+// readHeaderAt returns the header of the Data URI stored in ra,
+// up to and including the data comma.
+func readHeaderAt(ra io.ReaderAt, size int64) (string, error) {
+	var buf []byte
+	chunk := make([]byte, 512)
+	for int64(len(buf)) < size {
+		n, err := ra.ReadAt(chunk, int64(len(buf)))
+		buf = append(buf, chunk[:n]...)
+		if i := dataCommaIndex(string(buf)); i >= 0 {
+			return string(buf[:i+1]), nil
+		}
+		if err == io.EOF || int64(len(buf)) >= size {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(buf) > maxHeaderSize {
+			return "", errors.New("datauri: header too large")
+		}
+	}
+	return "", headerError(string(buf))
+}
+
+// headerError returns the error of header, holding no data comma.
+func headerError(header string) error {
+	// let the parser report the most accurate error
+	_, err := DecodeString(header)
+	if err == nil {
+		err = ErrMissingComma
+	}
+	return err
+}
+
+// Size returns the size of the decoded payload.
+func (h *Handle) Size() int64 {
+	return h.dataSize
+}
+
+// ReadAt implements the io.ReaderAt interface, over the decoded payload.
+func (h *Handle) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errInvalidRange
+	}
+	if off >= h.dataSize {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > h.dataSize {
+		end = h.dataSize
+	}
+
+	firstBlock, lastBlock := off/3, (end+2)/3
+	enc := make([]byte, min(lastBlock*4, h.encSize)-firstBlock*4)
+	if _, err := h.ra.ReadAt(enc, h.offset+firstBlock*4); err != nil && err != io.EOF {
+		return 0, err
+	}
+	data := make([]byte, h.enc.DecodedLen(len(enc)))
+	if _, err := h.enc.Decode(data, enc); err != nil {
+		return 0, err
+	}
+	skip := firstBlock * 3
+	n := copy(p, data[off-skip:end-skip])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,77 @@
+package datauri
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestOpenReaderAt(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 1000, 1001, 1002} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		s := New(data, "application/octet-stream", "name", `a "quoted", name`).String()
+		for _, s := range []string{s, strings.TrimRight(s, "=")} {
+			h, err := OpenReaderAt(strings.NewReader(s), int64(len(s)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.ContentType() != "application/octet-stream" || h.Params["name"] != `a "quoted", name` {
+				t.Errorf("Unexpected media type %s", h.MediaType.String())
+			}
+			if h.Size() != int64(size) {
+				t.Errorf("Expected size %d, got %d", size, h.Size())
+			}
+
+			got, err := io.ReadAll(io.NewSectionReader(h, 0, h.Size()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("Expected %v, got %v", data, got)
+			}
+
+			for off := 0; off < size; off += 97 {
+				p := make([]byte, 50)
+				n, err := h.ReadAt(p, int64(off))
+				expected := data[off:min(off+50, size)]
+				if n != len(expected) || !bytes.Equal(p[:n], expected) {
+					t.Errorf("ReadAt(%d): expected %v, got %v", off, expected, p[:n])
+				}
+				if n < len(p) && err != io.EOF {
+					t.Errorf("ReadAt(%d): expected io.EOF, got %v", off, err)
+				}
+			}
+			if _, err := h.ReadAt(make([]byte, 1), int64(size)); err != io.EOF {
+				t.Errorf("Expected io.EOF, got %v", err)
+			}
+		}
+	}
+}
+
+func TestOpenReaderAtErrors(t *testing.T) {
+	tests := []struct {
+		Input string
+		Err   error
+	}{
+		{`data:text/plain,heya`, nil},
+		{`data:text/plain;base64,aGV5YQ=`, nil},
+		{`data:text/plain;base64,aGV5Y`, nil},
+		{"data:text/plain;base64,aGV5\r\nYQ==", nil},
+		{`data:text/plain;base64`, ErrMissingComma},
+		{`data:text/plain;name=` + strings.Repeat("a", 2*maxHeaderSize), nil},
+		{`text/plain;base64,aGV5YQ==`, nil},
+	}
+	for _, test := range tests {
+		_, err := OpenReaderAt(strings.NewReader(test.Input), int64(len(test.Input)))
+		if err == nil {
+			t.Errorf("%.30s: expected error", test.Input)
+		} else if test.Err != nil && !errors.Is(err, test.Err) {
+			t.Errorf("%.30s: expected %v, got %v", test.Input, test.Err, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package datauri
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// typographicChars maps the typographic characters that word processors
+// substitute to their ASCII equivalents. The spaces, not allowed in a
+// header anyway, are removed.
+var typographicChars = map[rune]string{
+	'\u2018': "'", '\u2019': "'", '\u201A': "'", '\u2032': "'", // ‘ ’ ‚ ′
+	'\u201C': `"`, '\u201D': `"`, '\u201E': `"`, '\u2033': `"`, // “ ” „ ″
+	'\u2010': "-", '\u2011': "-", '\u2012': "-", '\u2013': "-", '\u2014': "-", '\u2015': "-",
+	'\u00A0': "", '\u2007': "", '\u202F': "", '\u200B': "", '\uFEFF': "",
+}
+
+// windows1252 maps the bytes of the typographic characters in
+// Windows-1252, found in inputs that went through a legacy encoding,
+// to their runes.
+var windows1252 = map[byte]rune{
+	0x91: '\u2018', 0x92: '\u2019', 0x93: '\u201C', 0x94: '\u201D',
+	0x96: '\u2013', 0x97: '\u2014', 0xA0: '\u00A0',
+}
+
+// WithHeaderRepair makes the decoder replace the typographic characters
+// found in the header of Data URIs which went through word processors,
+// such as curly quotes, dashes and non-breaking spaces, in UTF-8 or in
+// Windows-1252, with their ASCII equivalents, instead of failing.
+// Each replacement is a CorrectionTypographicChar.
+func WithHeaderRepair() Option {
+	return func(o *options) {
+		o.repairHeader = true
+	}
+}
+
+// repairHeaderChars returns s with the typographic characters of its header
+// replaced, and the number of bytes they were shortened by.
+func (o *options) repairHeaderChars(s string) (string, int, error) {
+	if !strings.HasPrefix(s, dataPrefix) {
+		return s, 0, nil
+	}
+	var (
+		buf     strings.Builder
+		last    int
+		inQuote bool
+	)
+	for i := len(dataPrefix); i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if inQuote && c == '\\' {
+				i++
+			} else if c == '"' {
+				inQuote = !inQuote
+			} else if !inQuote && c == dataComma {
+				break
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			r = windows1252[c]
+		}
+		repl, ok := typographicChars[r]
+		if !ok {
+			// reported by the lexer
+			i += size
+			continue
+		}
+		if err := o.correct(Correction{
+			Offset:      i,
+			Kind:        CorrectionTypographicChar,
+			Original:    s[i : i+size],
+			Replacement: repl,
+		}); err != nil {
+			return "", 0, err
+		}
+		buf.WriteString(s[last:i])
+		buf.WriteString(repl)
+		if repl == `"` {
+			inQuote = !inQuote
+		}
+		i += size
+		last = i
+	}
+	if last == 0 {
+		return s, 0, nil
+	}
+	buf.WriteString(s[last:])
+	return buf.String(), len(s) - buf.Len(), nil
+}
@@ -0,0 +1,174 @@
+package datauri
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule is a transformation of a DataURI, done in place, see Rewrite.
+type Rule func(du *DataURI) error
+
+// Rewriter applies a list of Rules to Data URIs, or to the Data URIs
+// embedded in documents.
+type Rewriter struct {
+	rules []Rule
+}
+
+// Rewrite returns a Rewriter applying rules in order, so that data
+// cleaning pipelines declare their transformations once:
+//
+//	rw := datauri.Rewrite(
+//		datauri.StripExtensionParams(),
+//		datauri.NormalizeCharset(),
+//		datauri.ForceBase64(),
+//	)
+//	html, err := rw.Document(html)
+func Rewrite(rules ...Rule) *Rewriter {
+	return &Rewriter{rules: rules}
+}
+
+// Apply applies the rules of rw to du, in place.
+// It stops at the first rule returning an error.
+func (rw *Rewriter) Apply(du *DataURI) error {
+	for _, rule := range rw.rules {
+		if err := rule(du); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// URI decodes the Data URI s with opts, applies the rules of rw to it
+// and returns it written back as a string.
+func (rw *Rewriter) URI(s string, opts ...Option) (string, error) {
+	du, err := DecodeString(s, opts...)
+	if err != nil {
+		return "", err
+	}
+	if err := rw.Apply(du); err != nil {
+		return "", err
+	}
+	return du.String(), nil
+}
+
+// Document applies the rules of rw to the Data URIs embedded in doc,
+// such as an HTML or CSS document, found as by FindAll, and returns doc
+// with them replaced. The candidates which fail to decode with opts are
+// left as is.
+func (rw *Rewriter) Document(doc string, opts ...Option) (string, error) {
+	matches, _ := findAll(doc, opts, nil)
+	if len(matches) == 0 {
+		return doc, nil
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, m := range matches {
+		if err := rw.Apply(m.DataURI); err != nil {
+			return "", fmt.Errorf("datauri: data URI at offset %d: %w", m.Start, err)
+		}
+		b.WriteString(doc[last:m.Start])
+		b.WriteString(m.DataURI.String())
+		last = m.End
+	}
+	b.WriteString(doc[last:])
+	return b.String(), nil
+}
+
+// charsetAliases maps the common aliases of charsets to their
+// preferred MIME name, lowercased.
+var charsetAliases = map[string]string{
+	"utf8":           "utf-8",
+	"ascii":          "us-ascii",
+	"ansi_x3.4-1968": "us-ascii",
+	"latin1":         "iso-8859-1",
+	"latin-1":        "iso-8859-1",
+	"iso8859-1":      "iso-8859-1",
+	"iso_8859-1":     "iso-8859-1",
+	"cp1252":         "windows-1252",
+}
+
+// NormalizeCharset returns a Rule writing the charset parameter in
+// lowercase, with its common aliases replaced by their preferred
+// MIME name, e.g. "UTF8" as "utf-8". The attribute is lowercased too.
+// The payload is left as is.
+func NormalizeCharset() Rule {
+	return func(du *DataURI) error {
+		charset, ok := lookupParam(&du.MediaType, "charset")
+		if !ok {
+			return nil
+		}
+		du.FilterParams(func(attribute, _ string) bool {
+			return !strings.EqualFold(attribute, "charset")
+		})
+		du.Params["charset"] = normalizeCharset(charset)
+		return nil
+	}
+}
+
+// normalizeCharset returns charset lowercased,
+// or its preferred MIME name if it is a common alias.
+func normalizeCharset(charset string) string {
+	charset = strings.ToLower(charset)
+	if alias, ok := charsetAliases[charset]; ok {
+		return alias
+	}
+	return charset
+}
+
+// ForceBase64 returns a Rule encoding the payload in base64.
+func ForceBase64() Rule {
+	return func(du *DataURI) error {
+		du.Encoding = EncodingBase64
+		return nil
+	}
+}
+
+// StripExtensionParams returns a Rule removing the namespaced
+// parameters, see MediaType.StripExtensions.
+func StripExtensionParams(prefixes ...string) Rule {
+	return func(du *DataURI) error {
+		du.StripExtensions(prefixes...)
+		return nil
+	}
+}
+
+// CapSize returns a Rule truncating the payload to maxBytes bytes,
+// see DataURI.TruncateData.
+func CapSize(maxBytes int) Rule {
+	return func(du *DataURI) error {
+		if len(du.Data) > maxBytes {
+			*du = *du.TruncateData(maxBytes)
+		}
+		return nil
+	}
+}
+
+// Resniff returns a Rule replacing the media type with the one detected
+// from the payload, as by EncodeBytes, unless it is a generic
+// one, such as application/octet-stream, which would lose information.
+// The parameters other than the charset are kept.
+func Resniff() Rule {
+	return func(du *DataURI) error {
+		detected := detectContentType(du.Data)
+		if isGenericContentType(detected) {
+			return nil
+		}
+		sniffed, err := NewChecked(nil, detected)
+		if err != nil {
+			return err
+		}
+		du.Type, du.Subtype = sniffed.Type, sniffed.Subtype
+		du.FilterParams(func(attribute, _ string) bool {
+			return !strings.EqualFold(attribute, "charset")
+		})
+		if du.Params == nil {
+			du.Params = make(map[string]string)
+		}
+		for k, v := range sniffed.Params {
+			du.Params[k] = v
+		}
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package datauri
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRewriteURI(t *testing.T) {
+	tests := []struct {
+		rules    []Rule
+		s        string
+		expected string
+	}{
+		{[]Rule{NormalizeCharset()}, "data:text/plain;Charset=UTF8,hey", "data:text/plain;charset=utf-8,hey"},
+		{[]Rule{NormalizeCharset()}, "data:text/plain;charset=Shift_JIS,hey", "data:text/plain;charset=shift_jis,hey"},
+		{[]Rule{NormalizeCharset()}, "data:image/png;base64,aGV5", "data:image/png;base64,aGV5"},
+		{[]Rule{ForceBase64()}, "data:,hey", "data:text/plain;charset=US-ASCII;base64,aGV5"},
+		{[]Rule{StripExtensionParams()}, "data:text/plain;x-a=1;vnd.b=2;name=c,hey", "data:text/plain;name=c,hey"},
+		{[]Rule{CapSize(2)}, "data:text/plain;charset=utf-8,h%C3%A9y", "data:text/plain;charset=utf-8,h"},
+		{[]Rule{CapSize(10)}, "data:,hey", "data:text/plain;charset=US-ASCII,hey"},
+		{[]Rule{Resniff()}, "data:application/octet-stream;name=a,GIF89a", "data:image/gif;name=a,GIF89a"},
+		{[]Rule{Resniff()}, "data:image/png;base64,aGV5", "data:image/png;base64,aGV5"},
+		{
+			[]Rule{Resniff(), NormalizeCharset(), ForceBase64()},
+			"data:application/octet-stream,%3Chtml%3E",
+			"data:text/html;charset=utf-8;base64,PGh0bWw+",
+		},
+	}
+	for _, test := range tests {
+		got, err := Rewrite(test.rules...).URI(test.s)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, got)
+		}
+	}
+}
+
+func TestRewriteDocument(t *testing.T) {
+	rw := Rewrite(StripExtensionParams(), ForceBase64())
+	doc := `<img src="data:image/gif;x-a=b,GIF8"><a href="data:invalid">x</a><p style="background:url(data:,hey)">`
+	expected := `<img src="data:image/gif;base64,R0lGOA=="><a href="data:invalid">x</a><p style="background:url(data:text/plain;charset=US-ASCII;base64,aGV5)">`
+	got, err := rw.Document(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	for _, doc := range []string{"no data URI", "metadata:text/plain,hi"} {
+		if got, _ := rw.Document(doc); got != doc {
+			t.Errorf("Expected %s, got %s", doc, got)
+		}
+	}
+
+	errTooBig := errors.New("too big")
+	rw = Rewrite(func(du *DataURI) error {
+		if len(du.Data) > 3 {
+			return errTooBig
+		}
+		return nil
+	})
+	if _, err := rw.Document(doc); !errors.Is(err, errTooBig) {
+		t.Errorf("Expected %v, got %v", errTooBig, err)
+	}
+}
@@ -1,33 +1,281 @@
 package datauri
 
 import (
-	"net/url"
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
 )
 
-// Escape implements URL escaping, as defined in RFC 2397 (http://tools.ietf.org/html/rfc2397).
-// It differs a bit from net/url's QueryEscape and QueryUnescape, e.g how spaces are treated (+ instead of %20):
+// The escaping functions implement URL escaping, as used by RFC 2397 (http://tools.ietf.org/html/rfc2397)
+// for the ASCII encoded payloads and by this package for the parameter values.
+// It differs a bit from net/url's QueryEscape and QueryUnescape, e.g how spaces are treated (%20 instead of +).
 //
-// Only ASCII chars are allowed. Reserved chars are escaped to their %xx form.
-// Unreserved chars are [a-z], [A-Z], [0-9], and -_.!~*\().
+// Escaping leaves ASCII letters and digits, the unreserved marks -_.~
+// and the $&+:=@ reserved characters as is. All other bytes, including
+// non-ASCII ones, are escaped to their %XX form, with uppercase hexadecimal
+// digits. This is the escaping of net/url's PathEscape.
+//
+// Unescaping comes in a lenient flavor, which only decodes %XX sequences,
+// and a strict one, which also rejects the characters that must not
+// appear unescaped in a URL.
+
+const upperhex = "0123456789ABCDEF"
+
+// shouldEscape reports whether c is escaped by the escaping functions.
+func shouldEscape(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return false
+	}
+	switch c {
+	case '-', '_', '.', '~', '$', '&', '+', ':', '=', '@':
+		return false
+	}
+	return true
+}
+
+// Escape escapes data to a string, see the escaping rules above.
 func Escape(data []byte) string {
-	return url.PathEscape(string(data))
+	return string(AppendEscape(nil, data))
 }
 
 // EscapeString is like Escape, but taking
 // a string as argument.
 func EscapeString(s string) string {
-	return url.PathEscape(s)
+	n := countEscapes(s)
+	if n == 0 {
+		return s
+	}
+	return string(appendEscape(make([]byte, 0, len(s)+2*n), s))
+}
+
+// AppendEscape appends the escaped form of data to dst
+// and returns the extended buffer.
+func AppendEscape(dst, data []byte) []byte {
+	return appendEscape(dst, string(data))
+}
+
+// NewEscapeWriter returns a writer escaping the data written to it
+// before writing it to w, see the escaping rules above.
+func NewEscapeWriter(w io.Writer) io.Writer {
+	return &escapeWriter{w: w}
+}
+
+type escapeWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (e *escapeWriter) Write(p []byte) (int, error) {
+	e.buf = AppendEscape(e.buf[:0], p)
+	if _, err := e.w.Write(e.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func countEscapes(s string) (n int) {
+	for i := 0; i < len(s); i++ {
+		if shouldEscape(s[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+func appendEscape(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if shouldEscape(c) {
+			dst = append(dst, '%', upperhex[c>>4], upperhex[c&15])
+		} else {
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// EscapeError reports an invalid or incomplete %XX sequence,
+// or a character which must be escaped, found while unescaping.
+type EscapeError struct {
+	// Offset is the index in the input of the '%' starting the sequence,
+	// or of the invalid character.
+	Offset int
+	// Sequence is the invalid sequence, truncated to 3 bytes,
+	// or the invalid character.
+	Sequence string
+}
+
+func (e *EscapeError) Error() string {
+	if !strings.HasPrefix(e.Sequence, "%") {
+		return fmt.Sprintf("invalid URL character %q at offset %d", e.Sequence, e.Offset)
+	}
+	return fmt.Sprintf("invalid URL escape %q at offset %d", e.Sequence, e.Offset)
+}
+
+func escapeError[T input](s T, i int) *EscapeError {
+	end := i + 3
+	if end > len(s) {
+		end = len(s)
+	}
+	return &EscapeError{Offset: i, Sequence: string(s[i:end])}
 }
 
 // Unescape unescapes a character sequence
 // escaped with Escape(String?).
+//
+// An invalid or incomplete %XX sequence is reported with an *EscapeError
+// holding its position. Unescape runs in linear time whatever its input.
 func Unescape(s string) ([]byte, error) {
-	res, err := url.PathUnescape(s)
-	return []byte(res), err
+	n := strings.Count(s, "%")
+	if n == 0 {
+		return []byte(s), nil
+	}
+	return unescape(s, n, false)
 }
 
 // UnescapeToString is like Unescape, but returning
 // a string.
 func UnescapeToString(s string) (string, error) {
-	return url.PathUnescape(s)
+	n := strings.Count(s, "%")
+	if n == 0 {
+		return s, nil
+	}
+	data, err := unescape(s, n, false)
+	return string(data), err
+}
+
+// UnescapeBytes is like Unescape, but taking
+// a byte slice as argument.
+func UnescapeBytes(b []byte) ([]byte, error) {
+	n := countByte(b, '%')
+	if n == 0 {
+		return append([]byte(nil), b...), nil
+	}
+	return unescape(b, n, false)
+}
+
+// UnescapeStrict is like Unescape, but also reports the characters
+// which must not appear unescaped in a URL, such as spaces, quotes
+// or non-ASCII characters, with an *EscapeError.
+func UnescapeStrict(s string) ([]byte, error) {
+	return unescape(s, strings.Count(s, "%"), true)
+}
+
+// unescape decodes s, which holds n '%' characters.
+// If strict is true, the characters of s are validated
+// with isURLCharRune.
+func unescape[T input](s T, n int, strict bool) ([]byte, error) {
+	size := len(s) - 2*n
+	if size < 0 {
+		// some sequences are incomplete, this will be reported below
+		size = 0
+	}
+	return appendUnescape(make([]byte, 0, size), s, strict)
+}
+
+// appendUnescape appends the unescaped form of s to dst.
+func appendUnescape[T input](dst []byte, s T, strict bool) ([]byte, error) {
+	data := dst
+	for i := 0; i < len(s); {
+		j := indexByte(s[i:], '%')
+		if j < 0 {
+			j = len(s) - i
+		}
+		if strict {
+			if k := scanTable(s[i:i+j], &urlCharTable); k >= 0 {
+				return nil, &EscapeError{Offset: i + k, Sequence: string(s[i+k : i+k+1])}
+			}
+		}
+		data = append(data, s[i:i+j]...)
+		i += j
+		if i == len(s) {
+			break
+		}
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return nil, escapeError(s, i)
+		}
+		data = append(data, unhex(s[i+1])<<4|unhex(s[i+2]))
+		i += 3
+	}
+	return data, nil
+}
+
+// checkEscapes returns the error appendUnescape would return for s,
+// without unescaping it.
+func checkEscapes[T input](s T) error {
+	for i := 0; i < len(s); i += 3 {
+		j := indexByte(s[i:], '%')
+		if j < 0 {
+			break
+		}
+		i += j
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return escapeError(s, i)
+		}
+	}
+	return nil
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10
+	}
+	return 0
+}
+
+// NewUnescapeReader returns a reader unescaping the data read from r,
+// like Unescape. Errors are reported with an *EscapeError holding
+// the offset of the invalid sequence in r.
+func NewUnescapeReader(r io.Reader) io.Reader {
+	return &unescapeReader{r: bufio.NewReader(r)}
+}
+
+// unescapeReader unescapes the data read from r,
+// like UnescapeStrict if strict is true.
+type unescapeReader struct {
+	r      *bufio.Reader
+	off    int // in the input, for errors
+	strict bool
+}
+
+func (u *unescapeReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if n > 0 && u.r.Buffered() == 0 {
+			// don't block with unescaped bytes at hand
+			return n, nil
+		}
+		c, err := u.r.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		if c == '%' {
+			seq, err := u.r.Peek(2)
+			if len(seq) < 2 || !isHex(seq[0]) || !isHex(seq[1]) {
+				if err == nil || err == io.EOF {
+					err = &EscapeError{Offset: u.off, Sequence: "%" + string(seq)}
+				}
+				return n, err
+			}
+			_, _ = u.r.Discard(2)
+			c = unhex(seq[0])<<4 | unhex(seq[1])
+			u.off += 2
+		} else if u.strict && !urlCharTable[c] {
+			return n, &EscapeError{Offset: u.off, Sequence: string(c)}
+		}
+		u.off++
+		p[n] = c
+		n++
+	}
+	return n, nil
 }
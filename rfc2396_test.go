@@ -2,8 +2,13 @@ package datauri
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 var tests = []struct {
@@ -67,3 +72,175 @@ func ExampleUnescapeToString() {
 	fmt.Println(s)
 	// Output: A brief note
 }
+
+func TestUnescapeErrors(t *testing.T) {
+	tests := []struct {
+		escaped          string
+		expectedOffset   int
+		expectedSequence string
+	}{
+		{"%", 0, "%"},
+		{"abc%", 3, "%"},
+		{"abc%4", 3, "%4"},
+		{"abc%4g", 3, "%4g"},
+		{"%zzabc", 0, "%zz"},
+		{"%20%20%2", 6, "%2"},
+		{"%%%%%%%%", 0, "%%%"},
+	}
+	for _, test := range tests {
+		for _, unescape := range []func(string) error{
+			func(s string) error { _, err := Unescape(s); return err },
+			func(s string) error { _, err := UnescapeToString(s); return err },
+		} {
+			err := unescape(test.escaped)
+			var escErr *EscapeError
+			if !errors.As(err, &escErr) {
+				t.Errorf("%q: expected *EscapeError, got %v", test.escaped, err)
+				continue
+			}
+			if escErr.Offset != test.expectedOffset || escErr.Sequence != test.expectedSequence {
+				t.Errorf("%q: expected %q at %d, got %q at %d", test.escaped,
+					test.expectedSequence, test.expectedOffset, escErr.Sequence, escErr.Offset)
+			}
+		}
+	}
+}
+
+func FuzzUnescape(f *testing.F) {
+	for _, test := range tests {
+		f.Add(test.escaped)
+	}
+	f.Add("%")
+	f.Add("%%41")
+	f.Add("%4")
+	f.Fuzz(func(t *testing.T, s string) {
+		expected, expectedErr := url.PathUnescape(s)
+		got, err := UnescapeToString(s)
+		if (err == nil) != (expectedErr == nil) {
+			t.Fatalf("%q: expected error %v, got %v", s, expectedErr, err)
+		}
+		if err == nil && got != expected {
+			t.Fatalf("%q: expected %q, got %q", s, expected, got)
+		}
+	})
+}
+
+func TestEscapeMatchesPathEscape(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	expected := url.PathEscape(string(all))
+	if got := Escape(all); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got := EscapeString(string(all)); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got := string(AppendEscape([]byte("data:,"), all)); got != "data:,"+expected {
+		t.Errorf("Expected %s, got %s", "data:,"+expected, got)
+	}
+}
+
+func TestUnescapeBytes(t *testing.T) {
+	for _, test := range tests {
+		unescaped, err := UnescapeBytes([]byte(test.escaped))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !bytes.Equal(unescaped, test.unescaped) {
+			t.Errorf("Expected %s, got %s", test.unescaped, unescaped)
+		}
+	}
+}
+
+func TestUnescapeStrict(t *testing.T) {
+	for _, test := range tests {
+		unescaped, err := UnescapeStrict(test.escaped)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !bytes.Equal(unescaped, test.unescaped) {
+			t.Errorf("Expected %s, got %s", test.unescaped, unescaped)
+		}
+	}
+
+	invalid := []struct {
+		escaped          string
+		expectedOffset   int
+		expectedSequence string
+	}{
+		{"A brief note", 1, " "},
+		{"A%20brief\"note", 9, "\""},
+		{"caf\xc3\xa9", 3, "\xc3"},
+		{"A%20brief%2", 9, "%2"},
+	}
+	for _, test := range invalid {
+		_, err := UnescapeStrict(test.escaped)
+		var escErr *EscapeError
+		if !errors.As(err, &escErr) {
+			t.Errorf("%q: expected *EscapeError, got %v", test.escaped, err)
+			continue
+		}
+		if escErr.Offset != test.expectedOffset || escErr.Sequence != test.expectedSequence {
+			t.Errorf("%q: expected %q at %d, got %q at %d", test.escaped,
+				test.expectedSequence, test.expectedOffset, escErr.Sequence, escErr.Offset)
+		}
+		if _, err := Unescape(test.escaped); err == nil && test.expectedSequence[0] == '%' {
+			t.Errorf("%q: expected lenient unescaping to fail too", test.escaped)
+		}
+	}
+}
+
+func ExampleAppendEscape() {
+	buf := []byte("data:,")
+	buf = AppendEscape(buf, []byte("A brief note"))
+	fmt.Println(string(buf))
+	// Output: data:,A%20brief%20note
+}
+
+func TestEscapeWriterUnescapeReader(t *testing.T) {
+	inputs := []string{"", "heya", "A brief note", "h\u00e9y\u00e0 %20 \x00\xff", strings.Repeat("a b", 10000)}
+	for _, input := range inputs {
+		var buf bytes.Buffer
+		w := NewEscapeWriter(&buf)
+		for i := 0; i < len(input); i += 7 {
+			if _, err := io.WriteString(w, input[i:min(i+7, len(input))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if buf.String() != EscapeString(input) {
+			t.Errorf("Expected %.30s, got %.30s", EscapeString(input), buf.String())
+		}
+
+		data, err := io.ReadAll(NewUnescapeReader(iotest.OneByteReader(&buf)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != input {
+			t.Errorf("Expected %.30q, got %.30q", input, data)
+		}
+	}
+}
+
+func TestUnescapeReaderErrors(t *testing.T) {
+	tests := []struct {
+		escaped        string
+		expectedOffset int
+	}{
+		{"heya%", 4},
+		{"heya%2", 4},
+		{"hey a%zz", 5},
+	}
+	for _, test := range tests {
+		_, err := io.ReadAll(NewUnescapeReader(strings.NewReader(test.escaped)))
+		var escErr *EscapeError
+		if !errors.As(err, &escErr) {
+			t.Errorf("%q: expected EscapeError, got %v", test.escaped, err)
+		} else if escErr.Offset != test.expectedOffset {
+			t.Errorf("%q: expected offset %d, got %d", test.escaped, test.expectedOffset, escErr.Offset)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package datauri
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// originals holds the *original of the DataURIs decoded with
+// WithRoundTrip, by their address, out of the DataURI struct so that
+// it can still be built with positional fields. The entry of a DataURI
+// is deleted by its finalizer.
+var (
+	originals     sync.Map
+	originalsSize atomic.Int64 // the entries of originals, for a fast path
+)
+
+// original records the string a DataURI was decoded from,
+// along with a snapshot of the decoded fields used to detect
+// modifications.
+type original struct {
+	s         string
+	mediaType MediaType
+	encoding  string
+	// the payload is identified by its length and backing array, rather
+	// than by its content, so that writing du does not read it twice
+	dataLen int
+	data    *byte
+}
+
+func newOriginal(s string, du *DataURI) *original {
+	params := make(map[string]string, len(du.Params))
+	for k, v := range du.Params {
+		params[k] = v
+	}
+	return &original{
+		s: s,
+		mediaType: MediaType{
+			Type:    du.Type,
+			Subtype: du.Subtype,
+			Params:  params,
+		},
+		encoding: du.Encoding,
+		dataLen:  len(du.Data),
+		data:     firstByte(du.Data),
+	}
+}
+
+// setOriginal records s as the string du was decoded from.
+func setOriginal(du *DataURI, s string) {
+	originals.Store(reflect.ValueOf(du).Pointer(), newOriginal(s, du))
+	originalsSize.Add(1)
+	runtime.SetFinalizer(du, forgetOriginal)
+}
+
+// forgetOriginal deletes the string du was decoded from, if any.
+func forgetOriginal(du *DataURI) {
+	if _, ok := originals.LoadAndDelete(reflect.ValueOf(du).Pointer()); ok {
+		originalsSize.Add(-1)
+		runtime.SetFinalizer(du, nil)
+	}
+}
+
+// originalOf returns the original of du, or nil if du was not decoded
+// with WithRoundTrip. Copies of a DataURI have none.
+func originalOf(du *DataURI) *original {
+	if originalsSize.Load() == 0 {
+		return nil
+	}
+	o, _ := originals.Load(reflect.ValueOf(du).Pointer())
+	orig, _ := o.(*original)
+	return orig
+}
+
+// matches reports whether du is unmodified since it was decoded. Its
+// payload is unmodified as long as Data is the same slice.
+func (o *original) matches(du *DataURI) bool {
+	return o.matchesHeader(du) &&
+		len(du.Data) == o.dataLen &&
+		firstByte(du.Data) == o.data
+}
+
+// matchesHeader reports whether the media type and encoding of du
+// are unmodified since it was decoded.
+func (o *original) matchesHeader(du *DataURI) bool {
+	if du.Type != o.mediaType.Type ||
+		du.Subtype != o.mediaType.Subtype ||
+		du.Encoding != o.encoding ||
+		len(du.Params) != len(o.mediaType.Params) {
+		return false
+	}
+	for k, v := range o.mediaType.Params {
+		if dv, ok := du.Params[k]; !ok || dv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// firstByte returns the address of the first byte of b, nil if b is empty.
+func firstByte(b []byte) *byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return &b[0]
+}
@@ -0,0 +1,130 @@
+package datauri
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithRoundTrip(t *testing.T) {
+	tests := []string{
+		`data:text/plain;charset=utf-8;foo=bar;base64,aGV5YQ==`,
+		`data:;charset=utf-8;foo=bar;base64,aGV5YQ==`,
+		`data:text/plain;foo="bar";charset=utf-8;base64,aGV5YQ==`,
+		`data:text/plain;charset=utf-8;foo="bar",A%20brief%20note`,
+		`data:text/plain;charset=utf-8;foo=bar,A%20brief%20note`,
+		`data:text/plain;utf8,A%20brief%20note`,
+		`data:,A%20br%69ef%20note`,
+		`data:;base64,aGV5` + "\n" + `YQ==`,
+	}
+	for _, s := range tests {
+		du, err := DecodeString(s, WithRoundTrip())
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := du.String(); got != s {
+			t.Errorf("Expected %s, got %s", s, got)
+		}
+		txt, err := du.MarshalText()
+		if err != nil {
+			t.Error(err)
+		} else if string(txt) != s {
+			t.Errorf("MarshalText: expected %s, got %s", s, txt)
+		}
+	}
+}
+
+func TestWithRoundTripDecoders(t *testing.T) {
+	const s = `data:text/plain;name="a b.txt";charset=UTF-8;base64,aGV5` + "\r\n" + `YQ`
+	decoders := []struct {
+		Name   string
+		Decode func(s string, opts ...Option) (*DataURI, error)
+	}{
+		{"DecodeString", DecodeString},
+		{"DecodeBytes", func(s string, opts ...Option) (*DataURI, error) {
+			return DecodeBytes([]byte(s), opts...)
+		}},
+		{"Decode", func(s string, opts ...Option) (*DataURI, error) {
+			return Decode(strings.NewReader(s), opts...)
+		}},
+	}
+	for _, d := range decoders {
+		du, err := d.Decode(s, WithRoundTrip())
+		if err != nil {
+			t.Errorf("%s: %v", d.Name, err)
+			continue
+		}
+		if got := du.String(); got != s {
+			t.Errorf("%s: expected %q, got %q", d.Name, s, got)
+		}
+		txt, err := du.AppendText([]byte("src="))
+		if err != nil {
+			t.Errorf("%s: %v", d.Name, err)
+		} else if string(txt) != "src="+s {
+			t.Errorf("%s: expected %q, got %q", d.Name, "src="+s, txt)
+		}
+	}
+}
+
+func TestWithRoundTripModified(t *testing.T) {
+	const s = `data:text/plain;foo="bar";charset=utf-8,A%20brief%20note`
+	tests := []struct {
+		Name   string
+		Modify func(du *DataURI)
+	}{
+		{"type", func(du *DataURI) { du.Type = "application" }},
+		{"subtype", func(du *DataURI) { du.Subtype = "html" }},
+		{"param changed", func(du *DataURI) { du.Params["foo"] = "baz" }},
+		{"param added", func(du *DataURI) { du.Params["name"] = "note" }},
+		{"param removed", func(du *DataURI) { delete(du.Params, "foo") }},
+		{"encoding", func(du *DataURI) { du.Encoding = EncodingBase64 }},
+		{"data replaced", func(du *DataURI) { du.Data = []byte("A brief noteA") }},
+		{"data resliced", func(du *DataURI) { du.Data = du.Data[1:] }},
+		{"data copied", func(du *DataURI) { du.Data = append([]byte(nil), du.Data...) }},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(s, WithRoundTrip())
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Modify(du)
+		expected := (&DataURI{MediaType: du.MediaType, Encoding: du.Encoding, Data: du.Data}).String()
+		if got := du.String(); got != expected {
+			t.Errorf("%s: expected %s, got %s", test.Name, expected, got)
+		}
+	}
+}
+
+func TestWithRoundTripCopy(t *testing.T) {
+	const s = `data:text/plain;foo="bar",A%20brief%20note`
+	du, err := DecodeString(s, WithRoundTrip())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := *du
+	if got, expected := cp.String(), `data:text/plain;foo=bar,A%20brief%20note`; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got := du.String(); got != s {
+		t.Errorf("Expected %s, got %s", s, got)
+	}
+}
+
+func TestWithRoundTripCollected(t *testing.T) {
+	before := originalsSize.Load()
+	for i := 0; i < 100; i++ {
+		if _, err := DecodeString(`data:,heya`, WithRoundTrip()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for originalsSize.Load() > before && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if size := originalsSize.Load(); size > before {
+		t.Errorf("Expected the source strings of the collected DataURIs to be dropped, %d left", size-before)
+	}
+}
@@ -0,0 +1,24 @@
+package datauri
+
+import "encoding/base64"
+
+// DecodedLen returns the size of the payload of the Data URI s once
+// decoded, from the length of its payload, without decoding it, e.g. to
+// enforce quotas before paying the cost of decoding. Only the header
+// of s is decoded. The size is exact for valid payloads.
+func DecodedLen(s string) (int64, error) {
+	du, payload, err := decodeHeader(s)
+	if err != nil {
+		return 0, err
+	}
+	return payloadSize(payload, du.Encoding), nil
+}
+
+// EncodedLen returns the length of the Data URI holding a payload of
+// dataLen bytes, of a MediaType parsed from mediatype and params, as
+// written by String with base64 encoding, e.g. for New or EncodeBytes,
+// to pre-allocate output buffers. mediatype and params must be valid,
+// as for New, or it will panic.
+func EncodedLen(dataLen int, mediatype string, params ...string) int {
+	return len(New(nil, mediatype, params...).header()) + base64.StdEncoding.EncodedLen(dataLen)
+}
@@ -0,0 +1,58 @@
+package datauri
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodedLen(t *testing.T) {
+	tests := []struct {
+		InputString string
+		Expected    int64
+	}{
+		{"data:,", 0},
+		{"data:,A%20brief%20note", 12},
+		{"data:;base64,aGV5YQ==", 4},
+		{"data:;base64,aGV5YQ", 4},
+		{"data:;base64,aGV5\r\nYQ==\r\n", 4},
+		{`data:text/plain;name="a,b";base64,aGV5YWE=`, 5},
+		{"data:image/png;base64," + strings.Repeat("AAAA", 1000), 3000},
+	}
+	for _, test := range tests {
+		got, err := DecodedLen(test.InputString)
+		if err != nil {
+			t.Errorf("%s: %v", test.InputString, err)
+			continue
+		}
+		if got != test.Expected {
+			t.Errorf("%s: expected %d, got %d", test.InputString, test.Expected, got)
+		}
+		if du, err := DecodeString(test.InputString); err != nil || int64(len(du.Data)) != got {
+			t.Errorf("%s: expected %d decoded bytes, got %v", test.InputString, got, err)
+		}
+	}
+	for _, s := range []string{"", "data:", "data:text/plain", "http://example.com"} {
+		if _, err := DecodedLen(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestEncodedLen(t *testing.T) {
+	tests := []struct {
+		DataLen    int
+		MediaType  string
+		ParamPairs []string
+	}{
+		{0, "text/plain", nil},
+		{4, "text/plain", []string{"charset", "utf-8"}},
+		{5, "image/png", nil},
+		{1000, "application/json", []string{"name", "a b"}},
+	}
+	for _, test := range tests {
+		s := New(make([]byte, test.DataLen), test.MediaType, test.ParamPairs...).String()
+		if got := EncodedLen(test.DataLen, test.MediaType, test.ParamPairs...); got != len(s) {
+			t.Errorf("%s: expected %d, got %d", s, len(s), got)
+		}
+	}
+}
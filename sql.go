@@ -0,0 +1,35 @@
+package datauri
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value implements the driver.Valuer interface, storing du as its
+// Data URI string. The zero DataURI is stored as NULL, while a Data URI
+// with an empty payload, like "data:,", is stored as is.
+func (du DataURI) Value() (driver.Value, error) {
+	if du.IsZero() {
+		return nil, nil
+	}
+	txt, err := du.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(txt), nil
+}
+
+// Scan implements the sql.Scanner interface, reading a Data URI stored
+// as a string or bytes. NULL is read as the zero DataURI.
+func (du *DataURI) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*du = DataURI{}
+		return nil
+	case string:
+		return du.UnmarshalText([]byte(src))
+	case []byte:
+		return du.UnmarshalText(src)
+	}
+	return fmt.Errorf("datauri: cannot scan %T", src)
+}
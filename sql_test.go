@@ -0,0 +1,65 @@
+package datauri
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+var (
+	_ driver.Valuer = DataURI{}
+	_ sql.Scanner   = &DataURI{}
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		DataURI  DataURI
+		Expected driver.Value
+	}{
+		{DataURI{}, nil},
+		{*New([]byte("heya"), "text/plain"), "data:text/plain;base64,aGV5YQ=="},
+		{*New([]byte{}, "text/plain"), "data:text/plain;base64,"},
+	}
+	for _, test := range tests {
+		v, err := test.DataURI.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != test.Expected {
+			t.Errorf("Expected %v, got %v", test.Expected, v)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		Src          any
+		ExpectedZero bool
+		ExpectedData string
+	}{
+		{nil, true, ""},
+		{"data:text/plain;base64,aGV5YQ==", false, "heya"},
+		{[]byte("data:,heya"), false, "heya"},
+		{"data:,", false, ""},
+	}
+	for _, test := range tests {
+		du := *New([]byte("previous"), "text/plain")
+		if err := du.Scan(test.Src); err != nil {
+			t.Fatal(err)
+		}
+		if du.IsZero() != test.ExpectedZero {
+			t.Errorf("%v: expected IsZero() to be %v", test.Src, test.ExpectedZero)
+		}
+		if string(du.Data) != test.ExpectedData {
+			t.Errorf("Expected %s, got %s", test.ExpectedData, du.Data)
+		}
+	}
+
+	var du DataURI
+	if err := du.Scan(42); err == nil {
+		t.Error("Expected error")
+	}
+	if err := du.Scan("data:text/plain"); err == nil {
+		t.Error("Expected error")
+	}
+}
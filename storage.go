@@ -0,0 +1,105 @@
+package datauri
+
+import (
+	"bytes"
+	"compress/flate"
+	"strings"
+)
+
+// Strategy is a way of storing a DataURI, as advised by StorageHint.
+type Strategy int
+
+// Storage strategies returned by StorageHint.
+const (
+	// StoreInline stores the Data URI as is.
+	StoreInline Strategy = iota
+	// StoreCompressed stores the Data URI inline, compressed.
+	StoreCompressed
+	// StoreOffloaded stores the payload elsewhere, e.g. in an object store.
+	StoreOffloaded
+)
+
+// String implements the Stringer interface.
+func (s Strategy) String() string {
+	switch s {
+	case StoreInline:
+		return "inline"
+	case StoreCompressed:
+		return "compressed"
+	case StoreOffloaded:
+		return "offloaded"
+	}
+	return "unknown"
+}
+
+// Thresholds holds the payload sizes, in bytes, used by StorageHint.
+type Thresholds struct {
+	// Inline is the size up to which payloads are stored inline.
+	Inline int
+	// Compressed is the size up to which compressible payloads
+	// are stored inline, compressed.
+	Compressed int
+}
+
+const (
+	// compressionProbeSize is the size of the payload prefix compressed
+	// to estimate the compressibility of the whole payload.
+	compressionProbeSize = 4096
+	// compressibleRatio is the compressed/original size ratio under which
+	// a payload is deemed compressible.
+	compressibleRatio = 0.8
+)
+
+// StorageHint advises how du should be stored, based on the size of
+// its payload, its media type and how well a sample of it compresses:
+//   - payloads up to t.Inline bytes are stored inline,
+//   - compressible payloads up to t.Compressed bytes are stored compressed,
+//   - others are offloaded.
+func (du *DataURI) StorageHint(t Thresholds) Strategy {
+	size := len(du.Data)
+	switch {
+	case size <= t.Inline:
+		return StoreInline
+	case size <= t.Compressed && du.compressible():
+		return StoreCompressed
+	}
+	return StoreOffloaded
+}
+
+// compressible reports whether the payload of du is worth compressing.
+func (du *DataURI) compressible() bool {
+	if isCompressedMediaType(&du.MediaType) {
+		return false
+	}
+	probe := du.Data
+	if len(probe) > compressionProbeSize {
+		probe = probe[:compressionProbeSize]
+	}
+	if len(probe) == 0 {
+		return false
+	}
+	var buf bytes.Buffer
+	zw, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	_, _ = zw.Write(probe)
+	_ = zw.Close()
+	return float64(buf.Len()) < float64(len(probe))*compressibleRatio
+}
+
+// isCompressedMediaType reports whether mt describes
+// a format that is already compressed.
+func isCompressedMediaType(mt *MediaType) bool {
+	t, st := strings.ToLower(mt.Type), strings.ToLower(mt.Subtype)
+	switch t {
+	case "audio", "video":
+		return true
+	case "image":
+		return st != "svg+xml" && st != "bmp" && st != "x-ms-bmp" && st != "tiff"
+	case "application":
+		switch st {
+		case "zip", "gzip", "x-gzip", "zstd", "x-bzip2", "x-xz",
+			"x-7z-compressed", "vnd.rar", "x-rar-compressed":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package datauri
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestStorageHint(t *testing.T) {
+	random := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(random)
+	text := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 200)
+
+	thresholds := Thresholds{Inline: 1000, Compressed: 100000}
+	tests := []struct {
+		DataURI  *DataURI
+		Expected Strategy
+	}{
+		{New([]byte("heya"), "text/plain"), StoreInline},
+		{New(random[:1000], "application/octet-stream"), StoreInline},
+		{New(text, "text/plain"), StoreCompressed},
+		{New(text, "image/svg+xml"), StoreCompressed},
+		{New(random, "application/octet-stream"), StoreOffloaded},
+		{New(text, "image/png"), StoreOffloaded},
+		{New(text, "application/zip"), StoreOffloaded},
+		{New(bytes.Repeat(text, 20), "text/plain"), StoreOffloaded},
+	}
+	for _, test := range tests {
+		if got := test.DataURI.StorageHint(thresholds); got != test.Expected {
+			t.Errorf("%s (%d bytes): expected %s, got %s",
+				test.DataURI.ContentType(), len(test.DataURI.Data), test.Expected, got)
+		}
+	}
+}
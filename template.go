@@ -0,0 +1,112 @@
+package datauri
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// TemplateOption configures the functions of FuncMap.
+type TemplateOption func(*templateOptions)
+
+type templateOptions struct {
+	maxDataSize int64
+}
+
+// WithMaxEmbedSize makes the functions of FuncMap fail, and so the
+// execution of the template, with a Violation of code CodeDataTooLarge,
+// matching ErrTooLarge, for payloads larger than n bytes.
+func WithMaxEmbedSize(n int64) TemplateOption {
+	return func(o *templateOptions) {
+		o.maxDataSize = n
+	}
+}
+
+// FuncMap returns functions embedding Data URIs in html/template
+// templates, without them being escaped as unsafe content:
+//   - datauriSrc returns a template.URL, e.g. for the src attribute
+//     of an img element: <img src="{{datauriSrc .Logo}}">,
+//   - datauriCSS returns a template.CSS url() value, e.g. for
+//     a background: <div style="background: {{datauriCSS .Logo}}">.
+//
+// Both take a *DataURI or a DataURI, a []byte whose media type is
+// detected as by EncodeBytes, or a Data URI string, which is decoded
+// first so that no other kind of URL is embedded.
+func FuncMap(opts ...TemplateOption) template.FuncMap {
+	o := &templateOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return template.FuncMap{
+		"datauriSrc": func(v any) (template.URL, error) {
+			s, err := o.embed(v)
+			return template.URL(s), err
+		},
+		"datauriCSS": func(v any) (template.CSS, error) {
+			s, err := o.embed(v)
+			if err != nil {
+				return "", err
+			}
+			// the escaped Data URI, of token media type and
+			// attributes, holds no quote
+			return template.CSS(`url("` + s + `")`), nil
+		},
+	}
+}
+
+// embed returns v written as a Data URI.
+func (o *templateOptions) embed(v any) (string, error) {
+	var du *DataURI
+	switch v := v.(type) {
+	case *DataURI:
+		du = v
+	case DataURI:
+		du = &v
+	case []byte:
+		du = newDetected(v, "")
+	case string:
+		var opts []Option
+		if o.maxDataSize > 0 {
+			opts = append(opts, WithMaxDataSize(o.maxDataSize))
+		}
+		var err error
+		if du, err = DecodeString(v, opts...); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("datauri: cannot embed a %T", v)
+	}
+	if du == nil {
+		return "", fmt.Errorf("datauri: cannot embed a nil DataURI")
+	}
+	if o.maxDataSize > 0 && int64(len(du.Data)) > o.maxDataSize {
+		return "", Violation{
+			Code:   CodeDataTooLarge,
+			Field:  "data",
+			Limit:  o.maxDataSize,
+			Actual: int64(len(du.Data)),
+		}
+	}
+	if err := checkTokens(&du.MediaType); err != nil {
+		return "", err
+	}
+	// a copy, which is not written back as decoded with WithRoundTrip,
+	// so that it is escaped, holding no quote
+	cp := *du
+	b, err := cp.AppendText(nil)
+	return string(b), err
+}
+
+// checkTokens fails if the type, subtype or a parameter attribute of mt,
+// written unescaped, is not a token: it could then end the url() value
+// or the attribute it is embedded in.
+func checkTokens(mt *MediaType) error {
+	if (mt.Type != "" || mt.Subtype != "") && (!isToken(mt.Type) || !isToken(mt.Subtype)) {
+		return fmt.Errorf("datauri: cannot embed the invalid mediatype %q", mt.Type+"/"+mt.Subtype)
+	}
+	for k := range mt.Params {
+		if !isToken(k) {
+			return fmt.Errorf("datauri: cannot embed the invalid param attribute %q", k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package datauri
+
+import (
+	"errors"
+	"html/template"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFuncMap(t *testing.T) {
+	tmpl := template.Must(template.New("").Funcs(FuncMap(WithMaxEmbedSize(16))).Parse(
+		`<img src="{{datauriSrc .}}"><div style="background: {{datauriCSS .}}"></div>`,
+	))
+	tests := []struct {
+		Data     any
+		Expected string
+	}{
+		{
+			New([]byte("heya"), "image/png"),
+			`<img src="data:image/png;base64,aGV5YQ=="><div style="background: url(&#34;data:image/png;base64,aGV5YQ==&#34;)"></div>`,
+		},
+		{
+			*New([]byte("heya"), "text/plain", "name", `a "b"`),
+			`<img src="data:text/plain;name=a%20%22b%22;base64,aGV5YQ=="><div style="background: url(&#34;data:text/plain;name=a%20%22b%22;base64,aGV5YQ==&#34;)"></div>`,
+		},
+		{
+			"data:,A%20brief%20note",
+			`<img src="data:text/plain;charset=US-ASCII,A%20brief%20note"><div style="background: url(&#34;data:text/plain;charset=US-ASCII,A%20brief%20note&#34;)"></div>`,
+		},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, test.Data); err != nil {
+			t.Error(err)
+			continue
+		}
+		if b.String() != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, b.String())
+		}
+	}
+
+	roundTrip, err := DecodeString(`data:text/plain;name=");x:y(",heya`, WithRoundTrip())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), `");x:y("`) {
+		t.Errorf("Expected the parameter to be escaped, got %s", b.String())
+	}
+
+	for _, data := range []any{
+		"javascript:alert(1)",
+		New([]byte("x"), "image/png", `a")}body{x:expression(1)}/*`, "v"),
+		&DataURI{MediaType: MediaType{Type: "image", Subtype: `png")}*{color:red}/*`}, Encoding: EncodingBase64},
+		&DataURI{MediaType: MediaType{Type: `image"`, Subtype: "png"}, Encoding: EncodingBase64},
+		New(make([]byte, 17), "image/png"),
+		New(make([]byte, 17), "image/png").String(),
+		42,
+	} {
+		if err := tmpl.Execute(&strings.Builder{}, data); err == nil {
+			t.Errorf("%v: expected an error, got nil", data)
+		}
+	}
+	err = tmpl.Execute(&strings.Builder{}, New(make([]byte, 17), "image/png"))
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected %v, got %v", ErrTooLarge, err)
+	}
+}
+
+func ExampleFuncMap() {
+	tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`<img src="{{datauriSrc .}}">`))
+	_ = tmpl.Execute(os.Stdout, New([]byte("heya"), "image/png"))
+	// Output: <img src="data:image/png;base64,aGV5YQ==">
+}
@@ -0,0 +1,70 @@
+package datauri
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedCharset is returned, wrapped, by Text for payloads
+// of a charset it cannot decode.
+var ErrUnsupportedCharset = errors.New("datauri: unsupported charset")
+
+// windows1252High maps the bytes 0x80 to 0x9F of windows-1252 to their
+// runes. The bytes undefined in windows-1252 are mapped to the C1
+// control characters of the same value, as browsers do.
+var windows1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+// Text returns the payload of du decoded as text according to its
+// charset, see Charset, as UTF-8. The supported charsets are:
+//   - UTF-8, whose payload must be valid,
+//   - US-ASCII, whose payload must only hold ASCII characters when the
+//     charset is explicit. Otherwise, as the charset is only the default
+//     one, a payload of valid UTF-8 is accepted too,
+//   - ISO-8859-1 and windows-1252.
+//
+// Payloads without charset, of binary media types such as
+// application/json, are read as UTF-8.
+func (du *DataURI) Text() (string, error) {
+	charset, explicit := du.Charset()
+	name := strings.ToLower(charset)
+	if alias, ok := charsetAliases[name]; ok {
+		name = alias
+	}
+	switch name {
+	case "", "utf-8":
+		if !utf8.Valid(du.Data) {
+			return "", errors.New("datauri: invalid UTF-8 text")
+		}
+		return string(du.Data), nil
+	case "us-ascii":
+		if !explicit && utf8.Valid(du.Data) {
+			return string(du.Data), nil
+		}
+		for i, c := range du.Data {
+			if c >= utf8.RuneSelf {
+				return "", fmt.Errorf("datauri: invalid %s text at offset %d", charset, i)
+			}
+		}
+		return string(du.Data), nil
+	case "iso-8859-1", "windows-1252":
+		var b strings.Builder
+		b.Grow(len(du.Data))
+		for _, c := range du.Data {
+			switch {
+			case c < utf8.RuneSelf:
+				b.WriteByte(c)
+			case c < 0xA0 && name == "windows-1252":
+				b.WriteRune(windows1252High[c-0x80])
+			default:
+				b.WriteRune(rune(c))
+			}
+		}
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("%w %s", ErrUnsupportedCharset, charset)
+}
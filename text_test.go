@@ -0,0 +1,61 @@
+package datauri
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected string
+	}{
+		{"data:,A%20brief%20note", "A brief note"},
+		{"data:text/plain;charset=utf-8,caf%C3%A9", "café"},
+		{"data:text/plain;charset=UTF8,caf%C3%A9", "café"},
+		{"data:text/plain;charset=iso-8859-1,caf%E9", "café"},
+		{"data:text/plain;charset=latin1,caf%E9%80", "café\u0080"},
+		{"data:text/plain;charset=windows-1252,%93caf%E9%94%20%80", "“café” €"},
+		{"data:text/plain;charset=cp1252,%81", "\u0081"},
+		{"data:application/json,%7B%22a%22%3A%22%C3%A9%22%7D", `{"a":"é"}`},
+		{"data:text/html,caf%C3%A9", "café"},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := du.Text()
+		if err != nil {
+			t.Errorf("%q: %v", test.s, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, got)
+		}
+	}
+
+	invalid := []struct {
+		s           string
+		unsupported bool
+	}{
+		{"data:text/plain;charset=us-ascii,caf%C3%A9", false},
+		{"data:text/plain;charset=utf-8,caf%E9", false},
+		{"data:,caf%E9", false},
+		{"data:application/json,%FF", false},
+		{"data:text/plain;charset=shift_jis,hey", true},
+	}
+	for _, test := range invalid {
+		du, err := DecodeString(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = du.Text()
+		if err == nil {
+			t.Errorf("%q: expected an error", test.s)
+		}
+		if errors.Is(err, ErrUnsupportedCharset) != test.unsupported {
+			t.Errorf("%q: expected unsupported %v, got %v", test.s, test.unsupported, err)
+		}
+	}
+}
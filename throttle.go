@@ -0,0 +1,53 @@
+package datauri
+
+import (
+	"io"
+	"time"
+)
+
+// WithRateLimit throttles the reading of Data URIs to bytesPerSec bytes
+// per second: of the input for Decode, of the decoded payload for
+// NewReader. This keeps the decoding of a giant Data URI from starving
+// the other users of a service.
+func WithRateLimit(bytesPerSec int) Option {
+	return func(o *options) {
+		o.rateLimit = bytesPerSec
+	}
+}
+
+// throttledReader delays its reads to let no more than rate bytes
+// through per second, on average since its first read.
+type throttledReader struct {
+	r     io.Reader
+	rate  int
+	start time.Time
+	n     int64
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func newThrottledReader(r io.Reader, rate int) *throttledReader {
+	return &throttledReader{
+		r:     r,
+		rate:  rate,
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if t.start.IsZero() {
+		t.start = t.now()
+	}
+	if len(p) > t.rate {
+		p = p[:t.rate]
+	}
+	n, err := t.r.Read(p)
+	t.n += int64(n)
+	due := time.Duration(float64(t.n) / float64(t.rate) * float64(time.Second))
+	if wait := due - t.now().Sub(t.start); wait > 0 {
+		t.sleep(wait)
+	}
+	return n, err
+}
@@ -0,0 +1,47 @@
+package datauri
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestThrottledReader(t *testing.T) {
+	var (
+		clock = time.Now()
+		slept time.Duration
+	)
+	tr := newThrottledReader(bytes.NewReader(make([]byte, 1000)), 100)
+	tr.now = func() time.Time { return clock.Add(slept) }
+	tr.sleep = func(d time.Duration) { slept += d }
+
+	n, err := io.Copy(io.Discard, tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1000 {
+		t.Errorf("Expected %d, got %d", 1000, n)
+	}
+	if slept != 10*time.Second {
+		t.Errorf("Expected %v, got %v", 10*time.Second, slept)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	start := time.Now()
+	r, err := NewReader(bytes.NewReader([]byte(`data:;base64,aGV5YWhleWFoZXlh`)), WithRateLimit(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "heyaheyaheya" {
+		t.Errorf("Expected %s, got %s", "heyaheyaheya", data)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected reading to take at least %v, got %v", 100*time.Millisecond, elapsed)
+	}
+}
@@ -0,0 +1,38 @@
+package datauri
+
+import "time"
+
+// Timing holds the time spent in the phases of decoding, see WithTiming.
+type Timing struct {
+	// Header is the time spent lexing and parsing the header,
+	// up to the data comma, including Params.
+	Header time.Duration
+	// Params is the time spent unescaping or unquoting parameter values.
+	Params time.Duration
+	// Payload is the time spent decoding the payload.
+	Payload time.Duration
+}
+
+// WithTiming makes the decoder add the time spent in each phase of
+// decoding to *stats, to attribute latency in production.
+// When decoding concurrently, each goroutine must use its own Timing.
+func WithTiming(stats *Timing) Option {
+	return func(o *options) {
+		o.timing = stats
+	}
+}
+
+// timeItem parses item, recording the time spent in o.timing.
+func (p *parser[T]) timeItem(item lexItem[T], start time.Time) error {
+	itemStart := time.Now()
+	err := p.parseItem(item)
+	switch item.t {
+	case itemParamVal:
+		p.opts.timing.Params += time.Since(itemStart)
+	case itemDataComma:
+		p.opts.timing.Header += time.Since(start)
+	case itemData:
+		p.opts.timing.Payload += time.Since(itemStart)
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+package datauri
+
+import "testing"
+
+func TestWithTiming(t *testing.T) {
+	var stats Timing
+	s := `data:text/plain;name=a%20b;base64,` + New(largePayload(), "text/plain").String()[len("data:text/plain;base64,"):]
+	if _, err := DecodeString(s, WithTiming(&stats)); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Header <= 0 || stats.Payload <= 0 {
+		t.Errorf("Expected all phases to be timed, got %+v", stats)
+	}
+	if stats.Params > stats.Header {
+		t.Errorf("Expected the params to be timed as part of the header, got %+v", stats)
+	}
+
+	first := stats
+	if _, err := DecodeString(`data:,heya`, WithTiming(&stats)); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Header < first.Header || stats.Payload < first.Payload {
+		t.Errorf("Expected durations to add up, got %+v then %+v", first, stats)
+	}
+}
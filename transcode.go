@@ -0,0 +1,59 @@
+package datauri
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// Transcode reads the Data URI in src and writes it to dst with its
+// payload encoded with to, EncodingBase64 or EncodingASCII. The payload
+// is streamed, never held in memory as a whole.
+//
+// On error, what was already written to dst is an incomplete Data URI.
+func Transcode(dst io.Writer, src io.Reader, to string, opts ...Option) error {
+	if to != EncodingBase64 && to != EncodingASCII {
+		return fmt.Errorf("datauri: invalid encoding %s", to)
+	}
+	r, err := NewReader(src, opts...)
+	if err != nil {
+		return err
+	}
+	du := &DataURI{MediaType: r.MediaType, Encoding: to}
+	if _, err := io.WriteString(dst, du.header()); err != nil {
+		return err
+	}
+
+	if to == EncodingASCII {
+		_, err := io.Copy(NewEscapeWriter(dst), r)
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, dst)
+	if _, err := io.Copy(enc, r); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// WithEncoding returns a copy of du whose payload is encoded with enc,
+// EncodingBase64 or EncodingASCII, e.g. to store Data URIs from many
+// sources in a single encoding. Any payload can be written in both
+// encodings, the ASCII one percent-encoding the bytes it needs to.
+func (du *DataURI) WithEncoding(enc string) (*DataURI, error) {
+	if enc != EncodingBase64 && enc != EncodingASCII {
+		return nil, fmt.Errorf("datauri: invalid encoding %s", enc)
+	}
+	params := make(map[string]string, len(du.Params))
+	for k, v := range du.Params {
+		params[k] = v
+	}
+	return &DataURI{
+		MediaType: MediaType{
+			Type:    du.Type,
+			Subtype: du.Subtype,
+			Params:  params,
+		},
+		Encoding: enc,
+		Data:     append([]byte(nil), du.Data...),
+	}, nil
+}
@@ -0,0 +1,89 @@
+package datauri
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTranscode(t *testing.T) {
+	tests := []struct {
+		Input    string
+		To       string
+		Expected string
+	}{
+		{`data:text/plain;charset=utf-8,A%20brief%20note`, EncodingBase64, `data:text/plain;charset=utf-8;base64,QSBicmllZiBub3Rl`},
+		{`data:text/plain;charset=utf-8;base64,QSBicmllZiBub3Rl`, EncodingASCII, `data:text/plain;charset=utf-8,A%20brief%20note`},
+		{`data:;base64,aGV5` + "\r\n" + `YQ==`, EncodingBase64, `data:text/plain;charset=US-ASCII;base64,aGV5YQ==`},
+		{`data:,`, EncodingBase64, `data:text/plain;charset=US-ASCII;base64,`},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := Transcode(&buf, strings.NewReader(test.Input), test.To); err != nil {
+			t.Errorf("%s: %v", test.Input, err)
+			continue
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, buf.String())
+		}
+	}
+}
+
+func TestTranscodeLarge(t *testing.T) {
+	data := largePayload()
+	var ascii, b64 bytes.Buffer
+	if err := Transcode(&ascii, strings.NewReader(New(data, "application/octet-stream").String()), EncodingASCII); err != nil {
+		t.Fatal(err)
+	}
+	if err := Transcode(&b64, &ascii, EncodingBase64); err != nil {
+		t.Fatal(err)
+	}
+	du, err := Decode(&b64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(du.Data, data) {
+		t.Error("Expected the payload to round trip")
+	}
+}
+
+func TestTranscodeErrors(t *testing.T) {
+	if err := Transcode(&bytes.Buffer{}, strings.NewReader(`data:,heya`), "base32"); err == nil {
+		t.Error("Expected error")
+	}
+	if err := Transcode(&bytes.Buffer{}, strings.NewReader(`data:;base64,aGV5Y!==`), EncodingASCII); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestWithEncoding(t *testing.T) {
+	tests := []struct {
+		InputString string
+		Encoding    string
+		Expected    string
+	}{
+		{"data:text/plain;charset=utf-8;base64,Y2Fmw6k=", EncodingASCII, "data:text/plain;charset=utf-8,caf%C3%A9"},
+		{"data:text/plain;charset=utf-8,caf%C3%A9", EncodingBase64, "data:text/plain;charset=utf-8;base64,Y2Fmw6k="},
+		{"data:,A%20brief%20note", EncodingBase64, "data:text/plain;charset=US-ASCII;base64,QSBicmllZiBub3Rl"},
+		{"data:application/octet-stream;base64,AAH/", EncodingASCII, "data:application/octet-stream,%00%01%FF"},
+	}
+	for _, test := range tests {
+		du, err := DecodeString(test.InputString, WithRoundTrip())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := du.WithEncoding(test.Encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, got.String())
+		}
+		if du.String() != test.InputString {
+			t.Errorf("Expected %s to be left unmodified, got %s", test.InputString, du.String())
+		}
+	}
+	if _, err := New(nil, "text/plain").WithEncoding("base32"); err == nil {
+		t.Error("Expected an error for an invalid encoding")
+	}
+}
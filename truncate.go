@@ -0,0 +1,46 @@
+package datauri
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncateData returns a copy of du whose payload holds at most maxBytes
+// bytes, e.g. for the preview of a text payload. Unlike slicing the
+// Data URI string, the result is a valid Data URI, encoded as du.
+//
+// For UTF-8 payloads, a multi-byte character is never cut in half:
+// the payload is truncated before it.
+func (du *DataURI) TruncateData(maxBytes int) *DataURI {
+	data := du.Data
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+	if len(data) > maxBytes {
+		n := maxBytes
+		if charset, _ := du.Charset(); isUTF8Charset(charset) {
+			for n > 0 && !utf8.RuneStart(data[n]) {
+				n--
+			}
+		}
+		data = data[:n]
+	}
+
+	params := make(map[string]string, len(du.Params))
+	for k, v := range du.Params {
+		params[k] = v
+	}
+	return &DataURI{
+		MediaType: MediaType{
+			Type:    du.Type,
+			Subtype: du.Subtype,
+			Params:  params,
+		},
+		Encoding: du.Encoding,
+		Data:     append([]byte(nil), data...),
+	}
+}
+
+func isUTF8Charset(charset string) bool {
+	return strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "utf8")
+}
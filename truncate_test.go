@@ -0,0 +1,32 @@
+package datauri
+
+import "testing"
+
+func TestTruncateData(t *testing.T) {
+	tests := []struct {
+		DataURI  *DataURI
+		MaxBytes int
+		Expected string
+	}{
+		{New([]byte("heya"), "text/plain"), 10, "data:text/plain;base64,aGV5YQ=="},
+		{New([]byte("heya"), "text/plain"), 3, "data:text/plain;base64,aGV5"},
+		{New([]byte("heya"), "text/plain"), 2, "data:text/plain;base64,aGU="},
+		{New([]byte("heya"), "text/plain"), -1, "data:text/plain;base64,"},
+		{New([]byte("héya"), "text/plain", "charset", "utf-8"), 2, "data:text/plain;charset=utf-8;base64,aA=="},
+		{New([]byte("héya"), "application/octet-stream"), 2, "data:application/octet-stream;base64,aMM="},
+		{&DataURI{MediaType: defaultMediaType(), Encoding: EncodingASCII, Data: []byte("A brief note")}, 7, "data:text/plain;charset=US-ASCII,A%20brief"},
+	}
+	for _, test := range tests {
+		orig := test.DataURI.String()
+		truncated := test.DataURI.TruncateData(test.MaxBytes)
+		if got := truncated.String(); got != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, got)
+		}
+		if _, err := DecodeString(truncated.String()); err != nil {
+			t.Error(err)
+		}
+		if test.DataURI.String() != orig {
+			t.Errorf("Expected %s to be left unmodified", orig)
+		}
+	}
+}
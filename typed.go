@@ -0,0 +1,152 @@
+package datauri
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// TypedDecoder decodes the payload of du into v,
+// a non-nil pointer to the value requested to DecodeTyped.
+type TypedDecoder func(du *DataURI, v any) error
+
+// TypedEncoder encodes v into the payload of a Data URI,
+// for EncodeTyped.
+type TypedEncoder func(v any) ([]byte, error)
+
+var (
+	typedMu  sync.RWMutex
+	decoders = map[string]TypedDecoder{
+		"application/json": decodeJSON,
+		"+json":            decodeJSON,
+		"text/csv":         decodeCSV,
+	}
+	encoders = map[string]TypedEncoder{
+		"application/json": json.Marshal,
+		"+json":            json.Marshal,
+		"text/csv":         encodeCSV,
+	}
+)
+
+// RegisterDecoder registers fn as the TypedDecoder used by DecodeTyped
+// for the media types matching pattern, which is either an exact
+// "type/subtype" media type, a "type/*" wildcard or a "+suffix"
+// structured syntax suffix. Exact matches are preferred over
+// wildcards, and wildcards over suffixes.
+//
+// The default decoders handle:
+//   - application/json and +json media types, with json.Unmarshal,
+//   - text/csv into a [][]string, with encoding/csv.
+//
+// The decoder of images is registered by importing the typedimage package.
+func RegisterDecoder(pattern string, fn TypedDecoder) {
+	typedMu.Lock()
+	defer typedMu.Unlock()
+	decoders[strings.ToLower(pattern)] = fn
+}
+
+// RegisterEncoder registers fn as the TypedEncoder used by EncodeTyped
+// for the media types matching pattern, following the same rules
+// as RegisterDecoder.
+//
+// The default encoders handle:
+//   - application/json and +json media types, with json.Marshal,
+//   - text/csv from a [][]string, with encoding/csv.
+//
+// The encoders of images are registered by importing the typedimage package.
+func RegisterEncoder(pattern string, fn TypedEncoder) {
+	typedMu.Lock()
+	defer typedMu.Unlock()
+	encoders[strings.ToLower(pattern)] = fn
+}
+
+// DecodeTyped decodes the Data URI s, then decodes its payload into
+// a value of type T with the TypedDecoder registered for its media type.
+func DecodeTyped[T any](s string, opts ...Option) (T, error) {
+	var v T
+	du, err := DecodeString(s, opts...)
+	if err != nil {
+		return v, err
+	}
+	fn := lookupPattern(&typedMu, decoders, du.Type, du.Subtype)
+	if fn == nil {
+		return v, fmt.Errorf("datauri: no decoder registered for %s", du.ContentType())
+	}
+	err = fn(du, &v)
+	return v, err
+}
+
+// EncodeTyped returns a DataURI of the given media type, which may
+// hold parameters, whose payload is v encoded with the TypedEncoder
+// registered for the media type.
+func EncodeTyped(v any, mediatype string) (*DataURI, error) {
+	mt, params, err := mime.ParseMediaType(mediatype)
+	if err != nil {
+		return nil, err
+	}
+	t, st, _ := strings.Cut(mt, "/")
+	fn := lookupPattern(&typedMu, encoders, t, st)
+	if fn == nil {
+		return nil, fmt.Errorf("datauri: no encoder registered for %s", mt)
+	}
+	data, err := fn(v)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]string, 0, 2*len(params))
+	for k, v := range params {
+		pairs = append(pairs, k, v)
+	}
+	return New(data, mt, pairs...), nil
+}
+
+// lookupPattern returns the value of registry, guarded by mu, registered
+// for the pattern best matching the type/subtype media type, or the zero value.
+func lookupPattern[F any](mu *sync.RWMutex, registry map[string]F, t, st string) F {
+	t, st = strings.ToLower(t), strings.ToLower(st)
+	_, suffix := splitSuffix(st)
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if fn, ok := registry[t+"/"+st]; ok {
+		return fn
+	}
+	if fn, ok := registry[t+"/*"]; ok {
+		return fn
+	}
+	if suffix != "" {
+		return registry["+"+suffix]
+	}
+	var zero F
+	return zero
+}
+
+func decodeJSON(du *DataURI, v any) error {
+	return json.Unmarshal(du.Data, v)
+}
+
+func decodeCSV(du *DataURI, v any) error {
+	records, ok := v.(*[][]string)
+	if !ok {
+		return fmt.Errorf("datauri: cannot decode %s into %T", du.ContentType(), v)
+	}
+	var err error
+	*records, err = csv.NewReader(bytes.NewReader(du.Data)).ReadAll()
+	return err
+}
+
+func encodeCSV(v any) ([]byte, error) {
+	records, ok := v.([][]string)
+	if !ok {
+		return nil, fmt.Errorf("datauri: cannot encode %T as text/csv", v)
+	}
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
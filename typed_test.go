@@ -0,0 +1,122 @@
+package datauri
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type message struct {
+	Msg string `json:"msg"`
+}
+
+func TestDecodeTyped(t *testing.T) {
+	msg, err := DecodeTyped[message](`data:application/json;base64,eyJtc2ciOiAiaGV5YSJ9`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Msg != "heya" {
+		t.Errorf("Expected heya, got %v", msg)
+	}
+
+	msgPtr, err := DecodeTyped[*message](`data:application/vnd.acme+json,%7B%22msg%22:%22heya%22%7D`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgPtr == nil || msgPtr.Msg != "heya" {
+		t.Errorf("Expected heya, got %v", msgPtr)
+	}
+
+	records, err := DecodeTyped[[][]string](`data:text/csv,a,b%0A1,2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [][]string{{"a", "b"}, {"1", "2"}}; !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected %v, got %v", expected, records)
+	}
+
+}
+
+func TestDecodeTypedErrors(t *testing.T) {
+	if _, err := DecodeTyped[string](`data:application/x-unknown,abc`); err == nil {
+		t.Error("Expected error for unregistered media type")
+	}
+	if _, err := DecodeTyped[string](`data:text/csv,a,b`); err == nil {
+		t.Error("Expected error for unsupported target type")
+	}
+	if _, err := DecodeTyped[message](`data:application/json`); !errors.Is(err, ErrMissingComma) {
+		t.Errorf("Expected decoding error, got %v", err)
+	}
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder("text/x-upper", func(du *DataURI, v any) error {
+		s, ok := v.(*string)
+		if !ok {
+			return errors.New("unsupported")
+		}
+		*s = string(bytes.ToUpper(du.Data))
+		return nil
+	})
+	s, err := DecodeTyped[string](`data:text/x-upper,heya`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "HEYA" {
+		t.Errorf("Expected HEYA, got %s", s)
+	}
+}
+
+func TestEncodeTyped(t *testing.T) {
+	tests := []struct {
+		Value     any
+		MediaType string
+		Expected  string
+	}{
+		{message{"heya"}, "application/json", `data:application/json;base64,eyJtc2ciOiJoZXlhIn0=`},
+		{&message{"heya"}, "application/vnd.acme+json; charset=utf-8", `data:application/vnd.acme+json;charset=utf-8;base64,eyJtc2ciOiJoZXlhIn0=`},
+		{[][]string{{"a", "b"}, {"1", "2"}}, "text/csv", `data:text/csv;base64,YSxiCjEsMgo=`},
+	}
+	for _, test := range tests {
+		du, err := EncodeTyped(test.Value, test.MediaType)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := du.String(); got != test.Expected {
+			t.Errorf("Expected %s, got %s", test.Expected, got)
+		}
+	}
+}
+
+func TestEncodeTypedErrors(t *testing.T) {
+	tests := []struct {
+		Value     any
+		MediaType string
+	}{
+		{"heya", "application/x-unknown"},
+		{"heya", "text/csv"},
+		{"heya", "image/png"},
+		{make(chan int), "application/json"},
+		{"heya", "application/json; charset"},
+	}
+	for _, test := range tests {
+		if _, err := EncodeTyped(test.Value, test.MediaType); err == nil {
+			t.Errorf("%T as %s: expected error", test.Value, test.MediaType)
+		}
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("text/x-upper", func(v any) ([]byte, error) {
+		return bytes.ToUpper([]byte(v.(string))), nil
+	})
+	du, err := EncodeTyped("heya", "text/x-upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(du.Data) != "HEYA" {
+		t.Errorf("Expected HEYA, got %s", du.Data)
+	}
+}
@@ -0,0 +1,57 @@
+// Package typedimage registers the image codecs of datauri.DecodeTyped
+// and datauri.EncodeTyped, for the programs which opt into them by
+// importing it for its side effects:
+//
+//	import _ "github.com/invopop/datauri/typedimage"
+//
+// It registers:
+//   - a TypedDecoder of image/* into an image.Image, with image.Decode,
+//   - TypedEncoders of image/png, image/jpeg and image/gif from an image.Image.
+//
+// Importing it also registers the PNG, JPEG and GIF formats with the
+// image package, as it imports image/png, image/jpeg and image/gif. The
+// other image formats must be registered as usual, e.g. by importing
+// golang.org/x/image/webp.
+package typedimage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/invopop/datauri"
+)
+
+func init() {
+	datauri.RegisterDecoder("image/*", decodeImage)
+	datauri.RegisterEncoder("image/png", encodeImage(func(b *bytes.Buffer, img image.Image) error { return png.Encode(b, img) }))
+	datauri.RegisterEncoder("image/jpeg", encodeImage(func(b *bytes.Buffer, img image.Image) error { return jpeg.Encode(b, img, nil) }))
+	datauri.RegisterEncoder("image/gif", encodeImage(func(b *bytes.Buffer, img image.Image) error { return gif.Encode(b, img, nil) }))
+}
+
+func decodeImage(du *datauri.DataURI, v any) error {
+	img, ok := v.(*image.Image)
+	if !ok {
+		return fmt.Errorf("datauri: cannot decode %s into %T", du.ContentType(), v)
+	}
+	var err error
+	*img, _, err = image.Decode(bytes.NewReader(du.Data))
+	return err
+}
+
+func encodeImage(encode func(*bytes.Buffer, image.Image) error) datauri.TypedEncoder {
+	return func(v any) ([]byte, error) {
+		img, ok := v.(image.Image)
+		if !ok {
+			return nil, fmt.Errorf("datauri: cannot encode %T as an image", v)
+		}
+		var buf bytes.Buffer
+		if err := encode(&buf, img); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
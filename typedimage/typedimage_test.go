@@ -0,0 +1,54 @@
+package typedimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/invopop/datauri"
+)
+
+func TestDecodeTyped(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 2, 3))
+	src.SetGray(1, 1, color.Gray{Y: 200})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	img, err := datauri.DecodeTyped[image.Image](datauri.New(buf.Bytes(), "image/png").String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != src.Bounds() || img.At(1, 1) != src.At(1, 1) {
+		t.Errorf("Unexpected decoded image %v", img.Bounds())
+	}
+
+	if _, err := datauri.DecodeTyped[string](datauri.New(buf.Bytes(), "image/png").String()); err == nil {
+		t.Error("Expected error for unsupported target type")
+	}
+}
+
+func TestEncodeTyped(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 2, 3))
+	for _, mt := range []string{"image/png", "image/jpeg", "image/gif"} {
+		du, err := datauri.EncodeTyped(src, mt)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		img, err := datauri.DecodeTyped[image.Image](du.String())
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if img.Bounds() != src.Bounds() {
+			t.Errorf("%s: expected bounds %v, got %v", mt, src.Bounds(), img.Bounds())
+		}
+	}
+
+	if _, err := datauri.EncodeTyped("heya", "image/png"); err == nil {
+		t.Error("Expected error for unsupported value type")
+	}
+}
@@ -0,0 +1,65 @@
+package datauri
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotURIList is returned when reading the URIs of a Data URI
+// whose media type is not text/uri-list.
+var ErrNotURIList = errors.New("datauri: not a text/uri-list")
+
+// NewURIList returns a DataURI of media type text/uri-list (RFC 2483)
+// holding uris, as handed by drag and drop operations.
+func NewURIList(uris ...string) *DataURI {
+	var b strings.Builder
+	for _, uri := range uris {
+		b.WriteString(uri)
+		b.WriteString("\r\n")
+	}
+	return New([]byte(b.String()), "text/uri-list")
+}
+
+// IsURIList reports whether du is of media type text/uri-list.
+func (du *DataURI) IsURIList() bool {
+	return strings.EqualFold(du.Type, "text") && strings.EqualFold(du.Subtype, "uri-list")
+}
+
+// URIs returns the URIs of a text/uri-list du, skipping the comments
+// and blank lines, or ErrNotURIList for any other media type.
+func (du *DataURI) URIs() ([]string, error) {
+	if !du.IsURIList() {
+		return nil, ErrNotURIList
+	}
+	var uris []string
+	for _, line := range strings.Split(string(du.Data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		uris = append(uris, line)
+	}
+	return uris, nil
+}
+
+// NestedDataURIs decodes the URIs of a text/uri-list du
+// which are themselves Data URIs.
+func (du *DataURI) NestedDataURIs(opts ...Option) ([]*DataURI, error) {
+	uris, err := du.URIs()
+	if err != nil {
+		return nil, err
+	}
+	var nested []*DataURI
+	for i, uri := range uris {
+		if len(uri) < len(dataPrefix) || !strings.EqualFold(uri[:len(dataPrefix)], dataPrefix) {
+			continue
+		}
+		n, err := DecodeString(uri, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("datauri: URI %d of the list: %w", i, err)
+		}
+		nested = append(nested, n)
+	}
+	return nested, nil
+}
@@ -0,0 +1,62 @@
+package datauri
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestURIList(t *testing.T) {
+	uris := []string{
+		"https://example.com/a.png",
+		"data:text/plain;charset=utf-8;base64,aGV5YQ==",
+		"data:,A%20brief%20note",
+	}
+	du, err := DecodeString(NewURIList(uris...).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !du.IsURIList() {
+		t.Errorf("Expected %s to be a uri-list", du.ContentType())
+	}
+	got, err := du.URIs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, uris) {
+		t.Errorf("Expected %v, got %v", uris, got)
+	}
+
+	nested, err := du.NestedDataURIs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nested) != 2 || string(nested[0].Data) != "heya" || string(nested[1].Data) != "A brief note" {
+		t.Errorf("Unexpected nested Data URIs %v", nested)
+	}
+}
+
+func TestURIListComments(t *testing.T) {
+	du := &DataURI{
+		MediaType: MediaType{Type: "text", Subtype: "URI-List", Params: map[string]string{}},
+		Encoding:  EncodingASCII,
+		Data:      []byte("# dropped from the browser\r\nhttps://example.com/\r\n\r\n#https://example.org/\nhttps://example.net/"),
+	}
+	expected := []string{"https://example.com/", "https://example.net/"}
+	got, err := du.URIs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestURIListErrors(t *testing.T) {
+	if _, err := New([]byte("heya"), "text/plain").URIs(); !errors.Is(err, ErrNotURIList) {
+		t.Errorf("Expected %v, got %v", ErrNotURIList, err)
+	}
+	if _, err := NewURIList("data:text/plain;base64").NestedDataURIs(); !errors.Is(err, ErrMissingComma) {
+		t.Errorf("Expected %v, got %v", ErrMissingComma, err)
+	}
+}
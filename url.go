@@ -0,0 +1,38 @@
+package datauri
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrNotDataURL is returned by ParseURL for a URL of another scheme
+// than data.
+var ErrNotDataURL = errors.New("datauri: not a data URL")
+
+// ParseURL decodes the Data URI held by u, a URL of the data scheme,
+// as returned by url.Parse: its header and payload are in u.Opaque,
+// and in u.RawQuery if the payload holds a '?'.
+// The fragment of u, which is not part of the data, is ignored.
+func ParseURL(u *url.URL, opts ...Option) (*DataURI, error) {
+	if !strings.EqualFold(u.Scheme, "data") {
+		return nil, ErrNotDataURL
+	}
+	if u.Opaque == "" {
+		return nil, errors.New("datauri: data URL without opaque data")
+	}
+	s := dataPrefix + u.Opaque
+	if u.ForceQuery || u.RawQuery != "" {
+		s += "?" + u.RawQuery
+	}
+	return DecodeString(s, opts...)
+}
+
+// URL returns du as a URL of the data scheme, holding du in Opaque
+// as written by String, so that u.String() == du.String().
+func (du *DataURI) URL() *url.URL {
+	return &url.URL{
+		Scheme: "data",
+		Opaque: strings.TrimPrefix(du.String(), dataPrefix),
+	}
+}
@@ -0,0 +1,71 @@
+package datauri
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		s                   string
+		expectedContentType string
+		expectedData        []byte
+	}{
+		{"data:,A%20brief%20note", "text/plain", []byte("A brief note")},
+		{"data:text/html,a?b=c", "text/html", []byte("a?b=c")},
+		{"data:text/html,a?", "text/html", []byte("a?")},
+		{"data:image/gif;base64,R0lGOA==#frag", "image/gif", []byte("GIF8")},
+		{"DATA:;base64,aGV5YQ==", "text/plain", []byte("heya")},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		du, err := ParseURL(u)
+		if err != nil {
+			t.Errorf("%q: %v", test.s, err)
+			continue
+		}
+		if du.ContentType() != test.expectedContentType {
+			t.Errorf("Expected %s, got %s", test.expectedContentType, du.ContentType())
+		}
+		if !bytes.Equal(du.Data, test.expectedData) {
+			t.Errorf("Expected %q, got %q", test.expectedData, du.Data)
+		}
+	}
+
+	u, _ := url.Parse("https://example.com/a.png")
+	if _, err := ParseURL(u); !errors.Is(err, ErrNotDataURL) {
+		t.Errorf("Expected %v, got %v", ErrNotDataURL, err)
+	}
+	if _, err := ParseURL(&url.URL{Scheme: "data"}); err == nil {
+		t.Error("Expected an error for an empty data URL")
+	}
+}
+
+func TestURL(t *testing.T) {
+	dus := []*DataURI{
+		New([]byte("heya"), "image/png", "name", "a b#c?d"),
+		{MediaType: defaultMediaType(), Encoding: EncodingASCII, Data: []byte("a?b#c d%")},
+	}
+	for _, du := range dus {
+		u := du.URL()
+		if u.String() != du.String() {
+			t.Errorf("Expected %s, got %s", du.String(), u.String())
+		}
+		parsed, err := url.Parse(u.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParseURL(parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != du.String() {
+			t.Errorf("Expected %s, got %s", du.String(), got.String())
+		}
+	}
+}
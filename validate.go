@@ -0,0 +1,55 @@
+package datauri
+
+import "encoding/base64"
+
+// validateQuanta is the number of base64 quanta, of 4 characters,
+// checked at once by validateData.
+const validateQuanta = 1024
+
+// Validate reports whether s is a valid Data URI, decoded with opts,
+// with the error DecodeString would return, e.g. for form validation.
+// The payload is checked without being decoded into a buffer: only
+// a small, fixed size one is used for base64 payloads.
+func Validate(s string, opts ...Option) error {
+	o := newOptions(opts)
+	o.validateOnly = true
+	_, err := decodeString(s, o)
+	return err
+}
+
+// validateData returns the error readData would return for the
+// payload s, without allocating a buffer for the decoded data.
+func (p *parser[T]) validateData(s T) error {
+	if p.du.Encoding != EncodingBase64 {
+		return checkEscapes(s)
+	}
+	// s is decoded by chunks of whole quanta, whose line breaks
+	// are ignored as by the decoder
+	var buf [3*validateQuanta + 8]byte
+	for start := 0; start < len(s); {
+		end, chars := start, 0
+		for ; end < len(s) && chars < 4*validateQuanta; end++ {
+			if c := s[end]; c != '\r' && c != '\n' {
+				chars++
+			}
+		}
+		n, err := decodeBase64(p.base64Enc, buf[:], s[start:end])
+		if ce, ok := err.(base64.CorruptInputError); ok {
+			return ce + base64.CorruptInputError(start)
+		}
+		if err != nil {
+			return err
+		}
+		if n < 3*validateQuanta {
+			// the payload was padded, only line breaks may follow
+			for i := end; i < len(s); i++ {
+				if c := s[i]; c != '\r' && c != '\n' {
+					return base64.CorruptInputError(i)
+				}
+			}
+			return nil
+		}
+		start = end
+	}
+	return nil
+}
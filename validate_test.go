@@ -0,0 +1,56 @@
+package datauri
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	quanta := strings.Repeat("aGV5", validateQuanta)
+	tests := []string{
+		"data:,heya",
+		"data:,A%20brief%20note",
+		"data:;base64,aGV5YQ==",
+		"data:;base64,aGV5YQ",
+		"data:;base64,aGV5\r\nYQ==\r\n",
+		"data:;base64," + quanta,
+		"data:;base64," + quanta + "YQ==",
+		"data:;base64," + quanta + "\r\n" + quanta + "\r\n",
+		"data:;base64," + quanta[4:] + "YQ==\r\n",
+		"data:text/plain;charset=utf-8,caf%C3%A9",
+		// invalid
+		"",
+		"data:text/plain",
+		"data:te(xt/plain,a",
+		"data:,a%2",
+		"data:,a%zz",
+		"data:;base64,%%%",
+		"data:;base64,YQ==YQ==",
+		"data:;base64," + quanta + "Y",
+		"data:;base64," + quanta[4:] + "YQ==" + quanta,
+		"data:;base64," + quanta[4:] + "YQ==\r\nY",
+		"data:;base64," + quanta + quanta[:10] + "!" + quanta,
+	}
+	for _, s := range tests {
+		_, expected := DecodeString(s)
+		err := Validate(s)
+		if (err == nil) != (expected == nil) || err != nil && err.Error() != expected.Error() {
+			t.Errorf("%.40q: expected %v, got %v", s, expected, err)
+		}
+	}
+
+	if err := Validate("data:,heya", WithMaxDataSize(2)); err == nil {
+		t.Error("Expected an error for a payload too large")
+	}
+}
+
+func BenchmarkValidateLarge(b *testing.B) {
+	s := "data:;base64," + strings.Repeat("aGV5", 1<<18)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Validate(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package datauri
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Versions of the output of the encoder, see V.
+const (
+	// Version1 is the output of the first releases, and the default.
+	Version1 = 1
+	// Version2 is the most compact output conforming to RFC 2397.
+	Version2 = 2
+)
+
+// V makes the encoder write Data URIs as its version n did, so that
+// consumers hashing or signing them only get different bytes when they
+// opt in to a newer version. Fixes which change the output are gated
+// behind a new version.
+//
+// Compared to Version1, Version2:
+//   - lowercases the media type and the parameter attributes, which are
+//     case-insensitive, before sorting the parameters,
+//   - omits the text/plain media type and the US-ASCII charset,
+//     which are the defaults of RFC 2397,
+//   - only escapes the payload characters which are not allowed in a URL,
+//     leaving e.g. '/', '?' and ',' as is.
+//
+// n must be one of the versions above or it will panic.
+func V(n int) EncodeOption {
+	if n < Version1 || n > Version2 {
+		panic(fmt.Sprintf("datauri: unknown encoder version %d", n))
+	}
+	return func(o *encodeOptions) {
+		o.version = n
+	}
+}
+
+// mediaTypeV2 returns mt as written by Version2, and whether
+// its type and subtype are omitted.
+func mediaTypeV2(mt MediaType) (MediaType, bool) {
+	keys := make([]string, 0, len(mt.Params))
+	for k := range mt.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make(map[string]string, len(mt.Params))
+	for _, k := range keys {
+		if lk := strings.ToLower(k); params[lk] == "" {
+			params[lk] = mt.Params[k]
+		}
+	}
+	v2 := MediaType{
+		Type:    strings.ToLower(mt.Type),
+		Subtype: strings.ToLower(mt.Subtype),
+		Params:  params,
+	}
+	omitted := v2.Type == "" && v2.Subtype == "" || v2.Type == "text" && v2.Subtype == "plain"
+	if omitted && strings.EqualFold(params["charset"], "US-ASCII") {
+		delete(params, "charset")
+	}
+	return v2, omitted
+}
+
+// appendEscapeV2 appends the escaped form of data to dst,
+// escaping only '%' and the characters not allowed in a URL.
+func appendEscapeV2(dst, data []byte) []byte {
+	for _, c := range data {
+		if c == '%' || !urlCharTable[c] {
+			dst = append(dst, '%', upperhex[c>>4], upperhex[c&15])
+		} else {
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
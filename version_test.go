@@ -0,0 +1,74 @@
+package datauri
+
+import "testing"
+
+func TestV(t *testing.T) {
+	tests := []struct {
+		DataURI    *DataURI
+		Opts       []EncodeOption
+		ExpectedV1 string
+		ExpectedV2 string
+	}{
+		{
+			&DataURI{MediaType: defaultMediaType(), Encoding: EncodingASCII, Data: []byte("a/b?c,d e%")},
+			nil,
+			`data:text/plain;charset=US-ASCII,a%2Fb%3Fc%2Cd%20e%25`,
+			`data:,a/b?c,d%20e%25`,
+		},
+		{
+			New([]byte("heya"), "Text/Plain", "Charset", "utf-8", "Name", "a b"),
+			nil,
+			`data:Text/Plain;Charset=utf-8;Name=a%20b;base64,aGV5YQ==`,
+			`data:;charset=utf-8;name=a%20b;base64,aGV5YQ==`,
+		},
+		{
+			New([]byte("heya"), "text/plain", "charset", "us-ascii"),
+			[]EncodeOption{AlwaysEmitMediaType()},
+			`data:text/plain;charset=us-ascii;base64,aGV5YQ==`,
+			`data:text/plain;base64,aGV5YQ==`,
+		},
+		{
+			New([]byte("heya"), "text/plain", "charset", "us-ascii"),
+			[]EncodeOption{AlwaysEmitMediaType(), AlwaysEmitCharset()},
+			`data:text/plain;charset=us-ascii;base64,aGV5YQ==`,
+			`data:text/plain;charset=US-ASCII;base64,aGV5YQ==`,
+		},
+		{
+			New([]byte("heya"), "IMAGE/PNG"),
+			nil,
+			`data:IMAGE/PNG;base64,aGV5YQ==`,
+			`data:image/png;base64,aGV5YQ==`,
+		},
+	}
+	for _, test := range tests {
+		if got := test.DataURI.StringWith(append(test.Opts, V(Version1))...); got != test.ExpectedV1 {
+			t.Errorf("Expected %s, got %s", test.ExpectedV1, got)
+		}
+		if got := test.DataURI.StringWith(test.Opts...); got != test.ExpectedV1 {
+			t.Errorf("Expected %s by default, got %s", test.ExpectedV1, got)
+		}
+		v2 := test.DataURI.StringWith(append(test.Opts, V(Version2))...)
+		if v2 != test.ExpectedV2 {
+			t.Errorf("Expected %s, got %s", test.ExpectedV2, v2)
+		}
+		du, err := DecodeString(v2)
+		if err != nil {
+			t.Errorf("%s: %v", v2, err)
+		} else if string(du.Data) != string(test.DataURI.Data) {
+			t.Errorf("Expected %s, got %s", test.DataURI.Data, du.Data)
+		}
+	}
+}
+
+func TestVUnknown(t *testing.T) {
+	for _, n := range []int{0, -1, Version2 + 1} {
+		func() {
+			defer func() {
+				if e := recover(); e == nil {
+					t.Errorf("V(%d): expected panic", n)
+				}
+			}()
+			V(n)
+		}()
+	}
+}